@@ -0,0 +1,69 @@
+// The-Nutrimancers-Codex/amplify/backend/accountHandlers.go
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/accounts"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/models"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/utils"
+)
+
+func (s *Server) registerHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.Credentials
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Error decoding request: "+err.Error())
+		return
+	}
+
+	user, err := s.accounts.Register(req.Username, req.Password)
+	switch {
+	case errors.Is(err, accounts.ErrUsernameTaken):
+		utils.RespondWithError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid registration: "+err.Error())
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, models.Account{ID: user.ID, Username: user.Username})
+}
+
+func (s *Server) loginHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.Credentials
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Error decoding request: "+err.Error())
+		return
+	}
+
+	user, token, expires, err := s.accounts.Login(req.Username, req.Password)
+	switch {
+	case errors.Is(err, accounts.ErrInvalidCredentials):
+		utils.RespondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	case err != nil:
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error logging in: "+err.Error())
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, models.Session{
+		Token:     token,
+		ExpiresAt: expires,
+		Account:   models.Account{ID: user.ID, Username: user.Username},
+	})
+}
+
+func (s *Server) logoutHandler(w http.ResponseWriter, r *http.Request) {
+	token, _ := accounts.BearerToken(r)
+	if err := s.accounts.Logout(token); err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error logging out: "+err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ID of the user accounts.RequireUser authenticated; per-user data is keyed by it
+func userID(r *http.Request) string {
+	user, _ := accounts.UserFrom(r.Context())
+	return user.ID
+}
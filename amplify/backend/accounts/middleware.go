@@ -0,0 +1,46 @@
+// The-Nutrimancers-Codex/amplify/backend/accounts/middleware.go
+package accounts
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/utils"
+)
+
+type contextKey struct{}
+
+// RequireUser rejects requests without a valid "Authorization: Bearer <token>" header
+// and puts the token's user on the request context for UserFrom
+func (s *Store) RequireUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := BearerToken(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="nutrimancer"`)
+			utils.RespondWithError(w, http.StatusUnauthorized, "Bearer token required")
+			return
+		}
+		user, err := s.Authenticate(token)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="nutrimancer", error="invalid_token"`)
+			utils.RespondWithError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, user)))
+	})
+}
+
+// UserFrom returns the user RequireUser authenticated
+func UserFrom(ctx context.Context) (User, bool) {
+	user, ok := ctx.Value(contextKey{}).(User)
+	return user, ok
+}
+
+func BearerToken(r *http.Request) (string, bool) {
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
@@ -0,0 +1,210 @@
+// The-Nutrimancers-Codex/amplify/backend/accounts/store.go
+package accounts
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrUsernameTaken      = errors.New("accounts: username already registered")
+	ErrInvalidCredentials = errors.New("accounts: invalid username or password")
+	ErrInvalidToken       = errors.New("accounts: invalid or expired token")
+)
+
+const (
+	minPasswordLength = 8
+	maxPasswordLength = 72 // bcrypt ignores anything longer
+	tokenBytes        = 32
+)
+
+// TokenTTL is how long a login stays valid
+var TokenTTL = 30 * 24 * time.Hour
+
+var (
+	usersBucket  = []byte("users")  // username -> User
+	tokensBucket = []byte("tokens") // sha256(token) -> session
+)
+
+// Compared against when the username is unknown, so a failed login takes as long either way and
+// doesn't reveal which usernames exist
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, err := bcrypt.GenerateFromPassword([]byte("not anyone's password"), bcrypt.DefaultCost)
+	if err != nil {
+		panic(err)
+	}
+	return hash
+})
+
+type User struct {
+	ID           string    `json:"id"`
+	Username     string    `json:"username"`
+	PasswordHash []byte    `json:"passwordHash"`
+	Created      time.Time `json:"created"`
+}
+
+// Only the token's hash is stored, so a copied database can't be used to log in
+type session struct {
+	UserID   string    `json:"userId"`
+	Username string    `json:"username"`
+	Expires  time.Time `json:"expires"`
+}
+
+// Store keeps users and their opaque bearer tokens in bbolt
+type Store struct {
+	db *bolt.DB
+}
+
+// New uses db for accounts; the caller owns and closes it
+func New(db *bolt.DB) (*Store, error) {
+	err := db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{usersBucket, tokensBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+/*=================================================================================================*/
+
+// Register creates a user with a bcrypt-hashed password
+func (s *Store) Register(username, password string) (User, error) {
+	username = normalizeUsername(username)
+	if username == "" {
+		return User{}, errors.New("username is required")
+	}
+	if len(password) < minPasswordLength || len(password) > maxPasswordLength {
+		return User{}, fmt.Errorf("password must be %d-%d characters", minPasswordLength, maxPasswordLength)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return User{}, err
+	}
+
+	user := User{Username: username, PasswordHash: hash, Created: time.Now().UTC()}
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(usersBucket)
+		if bucket.Get([]byte(username)) != nil {
+			return ErrUsernameTaken
+		}
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		user.ID = strconv.FormatUint(seq, 10)
+		return putJSON(bucket, []byte(username), user)
+	})
+	if err != nil {
+		return User{}, err
+	}
+	return user, nil
+}
+
+// Login checks the password and issues a new bearer token for the user. An unknown username
+// costs the same bcrypt comparison as a wrong password.
+func (s *Store) Login(username, password string) (User, string, time.Time, error) {
+	var user User
+	found := false
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(usersBucket).Get([]byte(normalizeUsername(username)))
+		if data == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(data, &user)
+	})
+	if err != nil {
+		return User{}, "", time.Time{}, err
+	}
+	hash := user.PasswordHash
+	if !found {
+		hash = dummyHash()
+	}
+	if bcrypt.CompareHashAndPassword(hash, []byte(password)) != nil || !found {
+		return User{}, "", time.Time{}, ErrInvalidCredentials
+	}
+
+	raw := make([]byte, tokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return User{}, "", time.Time{}, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	expires := time.Now().UTC().Add(TokenTTL)
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		return putJSON(tx.Bucket(tokensBucket), tokenKey(token), session{UserID: user.ID, Username: user.Username, Expires: expires})
+	})
+	if err != nil {
+		return User{}, "", time.Time{}, err
+	}
+	return user, token, expires, nil
+}
+
+// Authenticate resolves a bearer token to its user. It only reads, so requests don't queue
+// behind bbolt's single writer; an expired token is deleted in a write of its own.
+func (s *Store) Authenticate(token string) (User, error) {
+	var current session
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(tokensBucket).Get(tokenKey(token))
+		if data == nil {
+			return ErrInvalidToken
+		}
+		return json.Unmarshal(data, &current)
+	})
+	if err != nil {
+		return User{}, err
+	}
+	if time.Now().After(current.Expires) {
+		err := s.db.Update(func(tx *bolt.Tx) error {
+			return tx.Bucket(tokensBucket).Delete(tokenKey(token))
+		})
+		if err != nil {
+			return User{}, err
+		}
+		return User{}, ErrInvalidToken
+	}
+	return User{ID: current.UserID, Username: current.Username}, nil
+}
+
+// Logout revokes one token
+func (s *Store) Logout(token string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(tokensBucket).Delete(tokenKey(token))
+	})
+}
+
+/*=================================================================================================*/
+
+func normalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+func tokenKey(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return []byte(hex.EncodeToString(sum[:]))
+}
+
+func putJSON(bucket *bolt.Bucket, key []byte, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return bucket.Put(key, data)
+}
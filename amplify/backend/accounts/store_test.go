@@ -0,0 +1,199 @@
+// The-Nutrimancers-Codex/amplify/backend/accounts/store_test.go
+package accounts
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "accounts.db"), 0o600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	store, err := New(db)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return store
+}
+
+/*=================================================================================================*/
+
+func TestRegister(t *testing.T) {
+	store := newTestStore(t)
+	user, err := store.Register("  Ada ", "correct horse")
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if user.ID == "" || user.Username != "ada" {
+		t.Errorf("Register = %+v, want an ID and the normalized username", user)
+	}
+	if bcrypt.CompareHashAndPassword(user.PasswordHash, []byte("correct horse")) != nil {
+		t.Error("stored hash doesn't match the password")
+	}
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		want     error // nil: any error
+	}{
+		{"duplicate", "ada", "another password", ErrUsernameTaken},
+		{"duplicate in another case", "ADA", "another password", ErrUsernameTaken},
+		{"blank username", "   ", "correct horse", nil},
+		{"short password", "grace", "short", nil},
+		{"password past bcrypt's limit", "grace", string(make([]byte, maxPasswordLength+1)), nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := store.Register(test.username, test.password)
+			if err == nil || (test.want != nil && !errors.Is(err, test.want)) {
+				t.Errorf("Register = %v, want %v", err, test.want)
+			}
+		})
+	}
+}
+
+func TestLogin(t *testing.T) {
+	store := newTestStore(t)
+	registered, err := store.Register("ada", "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, token, expires, err := store.Login("Ada", "correct horse")
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if user.ID != registered.ID || token == "" || time.Until(expires) < TokenTTL-time.Minute {
+		t.Errorf("Login = %+v, %q, %v; want ada with a token valid for %v", user, token, expires, TokenTTL)
+	}
+	if _, second, _, _ := store.Login("ada", "correct horse"); second == token {
+		t.Error("two logins issued the same token")
+	}
+
+	for _, attempt := range []struct{ username, password string }{
+		{"ada", "wrong horse"},
+		{"grace", "correct horse"}, // unknown user: same error, after the same bcrypt work
+	} {
+		if _, _, _, err := store.Login(attempt.username, attempt.password); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("Login(%s, %s) = %v, want ErrInvalidCredentials", attempt.username, attempt.password, err)
+		}
+	}
+	if cost, err := bcrypt.Cost(dummyHash()); err != nil || cost != bcrypt.DefaultCost {
+		t.Errorf("dummy hash cost = %d, %v; want bcrypt.DefaultCost like real passwords", cost, err)
+	}
+}
+
+func TestTokens(t *testing.T) {
+	store := newTestStore(t)
+	registered, err := store.Register("ada", "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, token, _, err := store.Login("ada", "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, other, _, err := store.Login("ada", "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := store.Authenticate(token)
+	if err != nil || user.ID != registered.ID || user.Username != "ada" {
+		t.Errorf("Authenticate = %+v, %v; want ada", user, err)
+	}
+	if _, err := store.Authenticate("made-up"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Authenticate unknown token = %v, want ErrInvalidToken", err)
+	}
+
+	if err := store.Logout(token); err != nil {
+		t.Fatalf("Logout: %v", err)
+	}
+	if _, err := store.Authenticate(token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Authenticate after Logout = %v, want ErrInvalidToken", err)
+	}
+	if _, err := store.Authenticate(other); err != nil {
+		t.Errorf("other session after Logout = %v, want it still valid", err)
+	}
+}
+
+func TestExpiredToken(t *testing.T) {
+	store := newTestStore(t)
+	if _, err := store.Register("ada", "correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	defer func(ttl time.Duration) { TokenTTL = ttl }(TokenTTL)
+	TokenTTL = -time.Minute
+	_, token, _, err := store.Login("ada", "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Authenticate(token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Authenticate expired token = %v, want ErrInvalidToken", err)
+	}
+	store.db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(tokensBucket).Get(tokenKey(token)) != nil {
+			t.Error("expired token is still stored")
+		}
+		return nil
+	})
+}
+
+func TestRequireUser(t *testing.T) {
+	store := newTestStore(t)
+	if _, err := store.Register("ada", "correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	_, token, _, err := store.Login("ada", "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := store.RequireUser(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, _ := UserFrom(r.Context())
+		w.Write([]byte(user.Username))
+	}))
+
+	tests := []struct {
+		name          string
+		authorization string
+		status        int
+	}{
+		{"valid token", "Bearer " + token, http.StatusOK},
+		{"lowercase scheme", "bearer " + token, http.StatusOK},
+		{"no header", "", http.StatusUnauthorized},
+		{"basic auth", "Basic YWRhOnB3", http.StatusUnauthorized},
+		{"unknown token", "Bearer made-up", http.StatusUnauthorized},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/v1/journal/entries", nil)
+			if test.authorization != "" {
+				request.Header.Set("Authorization", test.authorization)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			if recorder.Code != test.status {
+				t.Errorf("status %d, want %d", recorder.Code, test.status)
+			}
+			if test.status == http.StatusOK && recorder.Body.String() != "ada" {
+				t.Errorf("handler saw user %q, want ada", recorder.Body)
+			}
+			if test.status == http.StatusUnauthorized && recorder.Header().Get("WWW-Authenticate") == "" {
+				t.Error("401 without a WWW-Authenticate header")
+			}
+		})
+	}
+}
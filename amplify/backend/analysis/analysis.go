@@ -0,0 +1,340 @@
+// The-Nutrimancers-Codex/amplify/backend/analysis/analysis.go
+//
+// Package analysis is the meal pipeline the server and the Lambda handlers share:
+// description -> ingredients -> nutrients -> gaps & recommendations.
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/config"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/derived"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/machinist"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/models"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/nutrients"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/services"
+)
+
+// Analyzer holds what an analysis reads: the parser and provider, the dataset and the thresholds.
+// New loads it once from the config; it's safe for concurrent use.
+type Analyzer struct {
+	Parser        services.IngredientParser // Gemini outside tests
+	Provider      services.NutrientProvider
+	FoodItems     []models.FoodItem
+	NutrientNames []string
+	Thresholds    nutrients.Thresholds
+	TopN          int // recommendations per list
+	Concurrency   int // a meal's ingredients looked up at once
+
+	// ObserveRecommender (if set) is told how long each recommendation list took: foods or complementaryProteins
+	ObserveRecommender func(kind string, start time.Time)
+}
+
+// New loads the thresholds and dataset the config names and builds its nutrient provider.
+// client (may be nil for the services default) is shared by Gemini and every provider;
+// wrap (may be nil) decorates the provider, e.g. with a cache.
+func New(cfg config.Config, client *http.Client, wrap func(services.NutrientProvider) (services.NutrientProvider, error)) (*Analyzer, error) {
+	a := &Analyzer{
+		Parser: services.Gemini{
+			Endpoint: cfg.Gemini.Endpoint,
+			Model:    cfg.Gemini.Model,
+			APIKey:   cfg.Gemini.APIKey,
+			Client:   client,
+		},
+		Thresholds:  nutrients.DefaultThresholds,
+		TopN:        cfg.Analysis.TopN,
+		Concurrency: cfg.Provider.Concurrency,
+	}
+
+	// Deficiency thresholds
+	if loaded, err := nutrients.LoadThresholds(cfg.Data.ThresholdsPath); err == nil {
+		a.Thresholds = loaded
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("loading thresholds: %v", err)
+	}
+	if cfg.Analysis.DefaultThreshold > 0 {
+		a.Thresholds.Default = cfg.Analysis.DefaultThreshold
+	}
+
+	// Machinist
+	var err error
+	a.FoodItems, a.NutrientNames, err = machinist.LoadFoodData(cfg.Data.DatasetPath)
+	if err != nil {
+		return nil, fmt.Errorf("loading food data: %v", err)
+	}
+
+	// Nutrient lookups - the offline provider indexes the dataset
+	options := providerOptions(cfg, a.FoodItems, client)
+	options.Wrap = wrap
+	if a.Provider, err = services.NewNutrientProvider(cfg.Provider.Name, options); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// providerOptions hands the upstream settings and the loaded dataset to services.NewNutrientProvider.
+// client (may be nil for the services default) is shared by every upstream.
+func providerOptions(cfg config.Config, foodItems []models.FoodItem, client *http.Client) services.ProviderOptions {
+	return services.ProviderOptions{
+		Nutritionix: services.Nutritionix{
+			Endpoint: cfg.Nutritionix.Endpoint,
+			AppID:    cfg.Nutritionix.AppID,
+			AppKey:   cfg.Nutritionix.AppKey,
+			Client:   client,
+		},
+		FDC: services.FoodDataCentral{
+			Endpoint:  cfg.FDC.Endpoint,
+			APIKey:    cfg.FDC.APIKey,
+			DataTypes: cfg.FDC.DataTypes,
+			Client:    client,
+		},
+		Dataset:  services.DatasetOptions{Foods: foodItems, MinConfidence: cfg.Provider.MinConfidence},
+		Fallback: cfg.Provider.Fallback,
+	}
+}
+
+/*=================================================================================================*/
+
+// Error is a failed analysis and the HTTP status it maps to
+type Error struct {
+	Status  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Progress is called as each stage of an analysis finishes, for streaming; nil when nobody is listening
+type Progress func(event models.AnalysisEvent, data any)
+
+func (p Progress) send(event models.AnalysisEvent, data any) {
+	if p != nil {
+		p(event, data)
+	}
+}
+
+// AnalyzeMeal extracts the description's ingredients, looks them up and judges the meal against
+// the profile. Cancelling ctx (the client going away) aborts the upstream calls still in flight.
+func (a *Analyzer) AnalyzeMeal(ctx context.Context, req models.ProcessFoodRequest, progress Progress) (models.ProcessFoodResponse, *Error) {
+	fail := func(status int, message string) (models.ProcessFoodResponse, *Error) {
+		return models.ProcessFoodResponse{}, &Error{Status: status, Message: message}
+	}
+
+	profile, mealContext, invalid := resolveRequest(req)
+	if invalid != nil {
+		return models.ProcessFoodResponse{}, invalid
+	}
+
+	// Extract {food, quantity, unit} items using the Gemini LLM - quantities go to the nutrient provider with the food
+	extractedIngredients, err := a.Parser.ExtractIngredients(ctx, req.FoodDescription)
+	if err != nil {
+		return fail(http.StatusInternalServerError, "Error extracting ingredients: "+err.Error())
+	}
+	cleanedIngredients := services.IngredientKeys(extractedIngredients) // LookupEach's keys
+	progress.send(models.EventIngredients, models.IngredientsExtracted{Ingredients: cleanedIngredients})
+
+	// Fetch nutrient data for each ingredient from the nutrient provider
+	var onResolved func(string, services.FoodNutrients)
+	if progress != nil {
+		onResolved = func(key string, result services.FoodNutrients) {
+			progress.send(models.EventIngredient, ResolveIngredient(key, result, profile))
+		}
+	}
+	lookups, err := services.LookupEach(ctx, a.Provider, extractedIngredients, a.Concurrency, onResolved)
+	if err != nil {
+		return fail(http.StatusInternalServerError, "Error fetching nutrient data: "+err.Error())
+	}
+	nutrientData := lookups.Nutrients
+
+	// Energy & macronutrients
+	nutrientData, macros := SplitMacros(nutrientData, profile)
+
+	// Calculate RDA percentages
+	nutrientDetails, err := CalculateNutrientDetails(nutrientData, profile.Targets())
+	if err != nil {
+		return fail(http.StatusInternalServerError, "Error converting nutrient units: "+err.Error())
+	}
+	nutrientPercentages := CalculateNutrientPercentages(nutrientDetails)
+
+	// Calculate total nutrients
+	totalNutrients := CalculateTotalNutrients(nutrientPercentages)
+
+	// Determine Deficiencies
+	gaps := DetermineNutrientGaps(totalNutrients, mealContext, a.Thresholds)
+	lowAndMissingNutrients := DetermineLowAndMissingNutrients(gaps)
+	excessNutrients := DetermineExcessNutrients(totalNutrients, profile)
+	untrackedNutrients := DetermineUntrackedNutrients(totalNutrients)
+
+	// Derived metrics (fatty-acid balance, protein quality) from absolute amounts
+	amounts, err := CalculateAmounts(nutrientData)
+	if err != nil {
+		return fail(http.StatusInternalServerError, "Error converting nutrient units: "+err.Error())
+	}
+	derivedMetrics := derived.Compute(amounts, macros.PerIngredient)
+	progress.send(models.EventTotals, models.TotalsComputed{
+		Nutrients:          totalNutrients,
+		MissingNutrients:   lowAndMissingNutrients,
+		UntrackedNutrients: untrackedNutrients,
+		Gaps:               gaps,
+		ExcessNutrients:    excessNutrients,
+		Macros:             macros,
+	})
+
+	// Generate Recommendations - deficiencies plus derived-metric targets
+	weights := derivedMetrics.RecommenderWeights()
+	for _, nutrient := range lowAndMissingNutrients {
+		if _, set := weights[nutrient]; !set {
+			weights[nutrient] = 1.0
+		}
+	}
+	recommendStart := time.Now()
+	topRecommendations := machinist.RecommendFoodsWeighted(a.FoodItems, a.NutrientNames, weights, a.TopN)
+	a.observeRecommender("foods", recommendStart)
+
+	// Complementary proteins when the meal's protein is incomplete
+	var complementaryProteins []string
+	if meal := derivedMetrics.Protein.Meal; meal.Score != nil && *meal.Score < 1.0 {
+		recommendStart = time.Now()
+		complementaryProteins = machinist.RecommendComplementaryProteins(a.FoodItems, meal.LimitingAminoAcid, a.TopN)
+		a.observeRecommender("complementaryProteins", recommendStart)
+	}
+	progress.send(models.EventRecommendations, models.RecommendationsReady{
+		Suggestions:           topRecommendations,
+		ComplementaryProteins: complementaryProteins,
+	})
+
+	return models.ProcessFoodResponse{
+		SchemaVersion:         models.SchemaVersion,
+		Ingredients:           cleanedIngredients,
+		Portions:              lookups.Portions,
+		Sources:               lookups.Sources,
+		Nutrients:             nutrientPercentages,
+		NutrientDetails:       nutrientDetails,
+		MissingNutrients:      lowAndMissingNutrients,
+		UntrackedNutrients:    untrackedNutrients,
+		Suggestions:           topRecommendations,
+		ComplementaryProteins: complementaryProteins,
+		Gaps:                  gaps,
+		ExcessNutrients:       excessNutrients,
+		Macros:                macros,
+		Derived:               derivedMetrics,
+		Profile:               profile,
+		Context:               mealContext,
+	}, nil
+}
+
+// Validate is AnalyzeMeal's request check on its own - a description, a known profile and
+// context - for callers that reject bad requests before starting any analysis
+func Validate(req models.ProcessFoodRequest) *Error {
+	_, _, err := resolveRequest(req)
+	return err
+}
+
+func resolveRequest(req models.ProcessFoodRequest) (nutrients.Profile, nutrients.MealContext, *Error) {
+	invalid := func(message string) (nutrients.Profile, nutrients.MealContext, *Error) {
+		return nutrients.Profile{}, "", &Error{Status: http.StatusBadRequest, Message: message}
+	}
+	if strings.TrimSpace(req.FoodDescription) == "" {
+		return invalid("foodDescription is required")
+	}
+	profile, err := ResolveProfile(req.Profile)
+	if err != nil {
+		return invalid("Invalid profile: " + err.Error())
+	}
+	mealContext, err := req.Context.Resolve()
+	if err != nil {
+		return invalid("Invalid context: " + err.Error())
+	}
+	return profile, mealContext, nil
+}
+
+// LookupFood adds one suggested food, at the provider's default serving, to the current meal totals
+func (a *Analyzer) LookupFood(ctx context.Context, req models.FetchNutrientDataRequest) (models.FetchNutrientDataResponse, *Error) {
+	fail := func(status int, message string) (models.FetchNutrientDataResponse, *Error) {
+		return models.FetchNutrientDataResponse{}, &Error{Status: status, Message: message}
+	}
+
+	profile, err := ResolveProfile(req.Profile)
+	if err != nil {
+		return fail(http.StatusBadRequest, "Invalid profile: "+err.Error())
+	}
+
+	// Fetch nutrient data for the suggested food from the nutrient provider
+	nutrientData, err := services.LookupFoods(ctx, a.Provider, []string{req.FoodDescription})
+	if err != nil {
+		return fail(http.StatusInternalServerError, "Error fetching nutrient data: "+err.Error())
+	}
+
+	// Calculate RDA percentages
+	nutrientData, _ = SplitMacros(nutrientData, profile)
+	nutrientDetails, err := CalculateNutrientDetails(nutrientData, profile.Targets())
+	if err != nil {
+		return fail(http.StatusInternalServerError, "Error converting nutrient units: "+err.Error())
+	}
+	nutrientPercentages := CalculateNutrientPercentages(nutrientDetails)
+
+	// Combine current nutrients with new nutrients
+	newTotalNutrients := make(map[string]float64)
+	for nutrient, amount := range req.CurrentNutrients {
+		newTotalNutrients[nutrient] = amount
+	}
+	for nutrient, amount := range nutrientPercentages[req.FoodDescription] {
+		newTotalNutrients[nutrient] += amount
+	}
+
+	// Determine which nutrients have changed
+	changedNutrients := []string{}
+	for nutrient := range nutrientPercentages[req.FoodDescription] {
+		changedNutrients = append(changedNutrients, nutrient)
+	}
+
+	return models.FetchNutrientDataResponse{
+		Nutrients:        newTotalNutrients,
+		NutrientDetails:  nutrientDetails[req.FoodDescription],
+		ChangedNutrients: changedNutrients,
+		ExcessNutrients:  DetermineExcessNutrients(newTotalNutrients, profile),
+		Profile:          profile,
+	}, nil
+}
+
+// ResolveIngredient is one ingredient's percentages as soon as its lookup returns, so the orbs can fill in early
+func ResolveIngredient(food string, result services.FoodNutrients, profile nutrients.Profile) models.IngredientResolved {
+	micronutrients, macros := SplitMacros(map[string]map[string]float64{food: result.Amounts}, profile)
+	resolved := models.IngredientResolved{
+		Ingredient:      food,
+		Portion:         result.Portion,
+		Nutrients:       map[string]float64{},
+		NutrientDetails: map[string]models.NutrientAmount{},
+		Macros:          macros.PerIngredient[food],
+	}
+	if result.Source.Provider != "" {
+		resolved.Source = &result.Source
+	}
+	// Unit errors surface from the full calculation right after the lookups
+	if details, err := CalculateNutrientDetails(micronutrients, profile.Targets()); err == nil {
+		resolved.NutrientDetails = details[food]
+		resolved.Nutrients = CalculateNutrientPercentages(details)[food]
+	}
+	return resolved
+}
+
+// ResolveProfile is the optional request profile resolved against the DRI table (default adult when omitted)
+func ResolveProfile(profile *nutrients.Profile) (nutrients.Profile, error) {
+	if profile == nil {
+		return nutrients.DefaultProfile.Resolve()
+	}
+	return profile.Resolve()
+}
+
+func (a *Analyzer) observeRecommender(kind string, start time.Time) {
+	if a.ObserveRecommender != nil {
+		a.ObserveRecommender(kind, start)
+	}
+}
@@ -0,0 +1,208 @@
+// The-Nutrimancers-Codex/amplify/backend/analysis/calculations.go
+package analysis
+
+import (
+	"fmt"
+
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/models"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/nutrients"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/units"
+)
+
+func DetermineLowAndMissingNutrients(gaps []models.NutrientGap) []string {
+	var lowAndMissingNutrients []string
+	for _, gap := range gaps {
+		if gap.Status == models.GapMissing || gap.Status == models.GapLow {
+			lowAndMissingNutrients = append(lowAndMissingNutrients, gap.Nutrient)
+		}
+	}
+	return lowAndMissingNutrients
+}
+
+// Compare each nutrient with the share of its target the meal context should cover
+func DetermineNutrientGaps(totalNutrients map[string]float64, mealContext nutrients.MealContext, thresholds nutrients.Thresholds) []models.NutrientGap {
+	gaps := []models.NutrientGap{}
+	expected := mealContext.ExpectedPercent()
+
+	for _, name := range nutrients.Names() {
+		percentage, exists := totalNutrients[name]
+		if nutrient, _ := nutrients.Lookup(name); !exists && nutrient.Sparse {
+			continue // not tracked by source, see DetermineUntrackedNutrients
+		}
+		gap := models.NutrientGap{
+			Nutrient:         name,
+			Percentage:       percentage,
+			ExpectedPercent:  expected,
+			ThresholdPercent: thresholds.LowPercent(name, mealContext),
+			GapPercent:       expected - percentage,
+		}
+		switch {
+		case !exists:
+			gap.Status = models.GapMissing
+		case percentage <= gap.ThresholdPercent:
+			gap.Status = models.GapLow
+		case percentage < expected:
+			gap.Status = models.GapShort
+		default:
+			gap.Status = models.GapMet
+		}
+		gaps = append(gaps, gap)
+	}
+
+	return gaps
+}
+
+// Sparse nutrients no ingredient reported - unknown rather than deficient
+func DetermineUntrackedNutrients(totalNutrients map[string]float64) []string {
+	untrackedNutrients := []string{}
+	for _, name := range nutrients.Names() {
+		if nutrient, _ := nutrients.Lookup(name); nutrient.Sparse {
+			if _, exists := totalNutrients[name]; !exists {
+				untrackedNutrients = append(untrackedNutrients, name)
+			}
+		}
+	}
+	return untrackedNutrients
+}
+
+// Flag nutrients approaching or over their Tolerable Upper Intake Level
+func DetermineExcessNutrients(totalNutrients map[string]float64, profile nutrients.Profile) []models.ExcessNutrient {
+	excessNutrients := []models.ExcessNutrient{}
+	targets := profile.Targets()
+	limits := profile.Limits()
+
+	for _, nutrient := range nutrients.Names() {
+		limit, hasLimit := limits[nutrient]
+		percentage, exists := totalNutrients[nutrient]
+		if !hasLimit || !exists {
+			continue
+		}
+		upperLimitPercent := percentage * targets[nutrient] / limit
+		if severity, excessive := nutrients.ClassifyExcess(upperLimitPercent); excessive {
+			excessNutrients = append(excessNutrients, models.ExcessNutrient{
+				Nutrient:          nutrient,
+				Percentage:        percentage,
+				UpperLimitPercent: upperLimitPercent,
+				Severity:          severity,
+			})
+		}
+	}
+
+	return excessNutrients
+}
+
+// ================================================================================================================
+
+// Macronutrients are reported as amounts, so split them off before the RDA math
+func SplitMacros(nutrientData map[string]map[string]float64, profile nutrients.Profile) (map[string]map[string]float64, models.Macros) {
+	micronutrientData := make(map[string]map[string]float64)
+	macros := NewMacros(profile)
+
+	for ingredient, amounts := range nutrientData {
+		micronutrients := make(map[string]float64)
+		ingredientMacros := make(map[string]float64)
+		for name, amount := range amounts {
+			if nutrient, exists := nutrients.Lookup(name); exists && nutrient.IsMacro() {
+				ingredientMacros[name] = amount
+				macros.Total[name] += amount
+			} else {
+				micronutrients[name] = amount
+			}
+		}
+		micronutrientData[ingredient] = micronutrients
+		macros.PerIngredient[ingredient] = ingredientMacros
+	}
+	return micronutrientData, macros
+}
+
+// Empty macro totals with the profile's targets
+func NewMacros(profile nutrients.Profile) models.Macros {
+	macros := models.Macros{
+		PerIngredient: make(map[string]map[string]float64),
+		Total:         make(map[string]float64),
+		Targets:       profile.MacroTargets(),
+		Units:         make(map[string]units.Unit),
+	}
+	for _, name := range nutrients.MacroNames() {
+		nutrient, _ := nutrients.Lookup(name)
+		macros.Total[name] = 0
+		macros.Units[name] = nutrient.Unit
+	}
+	return macros
+}
+
+// Amount, unit, target and percentage of RDA (targets from the profile's DRI life-stage group)
+func CalculateNutrientDetails(nutrientData map[string]map[string]float64, targets map[string]float64) (map[string]map[string]models.NutrientAmount, error) {
+	detailsPerIngredient := make(map[string]map[string]models.NutrientAmount)
+	for ingredient, amounts := range nutrientData {
+		details := make(map[string]models.NutrientAmount)
+		for name, amount := range amounts {
+			nutrient, exists := nutrients.Lookup(name)
+			target, targetExists := targets[name]
+			detail := models.NutrientAmount{Amount: amount, Unit: nutrient.Unit}
+			if exists && targetExists {
+				// match units
+				adjustedAmount, err := units.ToMilligrams(name, units.Quantity{Value: amount, Unit: nutrient.Unit})
+				if err != nil {
+					return nil, fmt.Errorf("%s in %s: %v", name, ingredient, err)
+				}
+				displayTarget, err := units.Convert(name, units.Quantity{Value: target, Unit: units.Milligram}, nutrient.Unit)
+				if err != nil {
+					return nil, fmt.Errorf("%s target: %v", name, err)
+				}
+				detail.Target = displayTarget.Value
+				detail.Percentage = (adjustedAmount / target) * 100
+			}
+			details[name] = detail
+		}
+		detailsPerIngredient[ingredient] = details
+	}
+	return detailsPerIngredient, nil
+}
+
+// Flat percentage map - the original response shape
+func CalculateNutrientPercentages(nutrientDetails map[string]map[string]models.NutrientAmount) map[string]map[string]float64 {
+	percentagesPerIngredient := make(map[string]map[string]float64)
+	for ingredient, details := range nutrientDetails {
+		percentages := make(map[string]float64)
+		for name, detail := range details {
+			percentages[name] = detail.Percentage
+		}
+		percentagesPerIngredient[ingredient] = percentages
+	}
+	return percentagesPerIngredient
+}
+
+// Absolute amounts per ingredient, in mg
+func CalculateAmounts(nutrientData map[string]map[string]float64) (map[string]map[string]float64, error) {
+	amountsPerIngredient := make(map[string]map[string]float64)
+	for ingredient, amounts := range nutrientData {
+		amountsMg := make(map[string]float64)
+		for name, amount := range amounts {
+			nutrient, exists := nutrients.Lookup(name)
+			if !exists {
+				continue
+			}
+			mg, err := units.ToMilligrams(name, units.Quantity{Value: amount, Unit: nutrient.Unit})
+			if err != nil {
+				return nil, fmt.Errorf("%s in %s: %v", name, ingredient, err)
+			}
+			amountsMg[name] = mg
+		}
+		amountsPerIngredient[ingredient] = amountsMg
+	}
+	return amountsPerIngredient, nil
+}
+
+func CalculateTotalNutrients(nutrientPercentages map[string]map[string]float64) map[string]float64 {
+	totalNutrients := make(map[string]float64)
+
+	for _, nutrients := range nutrientPercentages {
+		for nutrient, percentage := range nutrients {
+			totalNutrients[nutrient] += percentage
+		}
+	}
+
+	// Uncapped - over-consumption is reported through DetermineExcessNutrients
+	return totalNutrients
+}
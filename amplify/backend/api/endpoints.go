@@ -0,0 +1,248 @@
+// The-Nutrimancers-Codex/amplify/backend/api/endpoints.go
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/config"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/models"
+)
+
+const Version = "v1"
+
+// Endpoint documents one operation; the OpenAPI document is generated from this table
+// and NewHandler refuses to start unless every endpoint has exactly one handler.
+type Endpoint struct {
+	ID       string // operationId, also the key handlers are registered under
+	Method   string
+	Path     string // may contain {name} path parameters
+	Summary  string
+	Request  any               // zero value of the JSON body type, nil for none
+	Response any               // zero value of the JSON response type, nil for 204
+	Status   int               // success status when not 200/204
+	Query    map[string]string // optional query parameters -> description
+	Auth     bool              // requires a user's bearer token
+	Admin    bool              // requires the admin token
+	Events   []Event           // Server-Sent Events in the order sent; replaces Response with text/event-stream
+}
+
+// Event is one named Server-Sent Event and the JSON type of its data
+type Event struct {
+	Name string
+	Data any
+}
+
+var Endpoints = []Endpoint{
+	{
+		ID:       "analyzeMeal",
+		Method:   http.MethodPost,
+		Path:     "/v1/meal-analyses",
+		Summary:  "Extract ingredients from a food description and analyze their nutrients",
+		Request:  models.ProcessFoodRequest{},
+		Response: models.ProcessFoodResponse{},
+		Auth:     true,
+	},
+	{
+		ID:       "analyzeMealBatch",
+		Method:   http.MethodPost,
+		Path:     "/v1/meal-analyses/batch",
+		Summary:  "Analyze many food descriptions concurrently; results and per-item errors in input order",
+		Request:  models.BatchProcessFoodRequest{},
+		Response: models.BatchProcessFoodResponse{},
+		Auth:     true,
+	},
+	{
+		ID:      "analyzeMealStream",
+		Method:  http.MethodPost,
+		Path:    "/v1/meal-analyses/stream",
+		Summary: "Analyze a food description, streaming each stage as it finishes",
+		Request: models.ProcessFoodRequest{},
+		Events: []Event{
+			{string(models.EventIngredients), models.IngredientsExtracted{}},
+			{string(models.EventIngredient), models.IngredientResolved{}},
+			{string(models.EventTotals), models.TotalsComputed{}},
+			{string(models.EventRecommendations), models.RecommendationsReady{}},
+			{string(models.EventResult), models.ProcessFoodResponse{}},
+			{string(models.EventError), models.AnalysisFailed{}},
+		},
+		Auth: true,
+	},
+	{
+		ID:       "lookupFood",
+		Method:   http.MethodPost,
+		Path:     "/v1/food-lookups",
+		Summary:  "Add one food's nutrients to the current meal totals",
+		Request:  models.FetchNutrientDataRequest{},
+		Response: models.FetchNutrientDataResponse{},
+		Auth:     true,
+	},
+
+	// Accounts
+	{
+		ID:       "register",
+		Method:   http.MethodPost,
+		Path:     "/v1/accounts",
+		Summary:  "Register a user",
+		Request:  models.Credentials{},
+		Response: models.Account{},
+		Status:   http.StatusCreated,
+	},
+	{
+		ID:       "login",
+		Method:   http.MethodPost,
+		Path:     "/v1/sessions",
+		Summary:  "Log in and receive a bearer token",
+		Request:  models.Credentials{},
+		Response: models.Session{},
+		Status:   http.StatusCreated,
+	},
+	{
+		ID:      "logout",
+		Method:  http.MethodDelete,
+		Path:    "/v1/sessions/current",
+		Summary: "Revoke the bearer token used for this request",
+		Auth:    true,
+	},
+
+	// Meal journal - scoped to the authenticated user
+	{
+		ID:       "createJournalEntry",
+		Method:   http.MethodPost,
+		Path:     "/v1/journal/entries",
+		Summary:  "Save an analysis to the journal under a meal slot",
+		Request:  models.JournalEntryRequest{},
+		Response: models.JournalEntry{},
+		Status:   http.StatusCreated,
+		Auth:     true,
+	},
+	{
+		ID:       "listJournalEntries",
+		Method:   http.MethodGet,
+		Path:     "/v1/journal/entries",
+		Summary:  "List journal entries in time order",
+		Response: []models.JournalEntry{},
+		Query:    map[string]string{"date": "only entries on this day (YYYY-MM-DD)"},
+		Auth:     true,
+	},
+	{
+		ID:       "getJournalEntry",
+		Method:   http.MethodGet,
+		Path:     "/v1/journal/entries/{id}",
+		Summary:  "Fetch one journal entry",
+		Response: models.JournalEntry{},
+		Auth:     true,
+	},
+	{
+		ID:       "updateJournalEntry",
+		Method:   http.MethodPut,
+		Path:     "/v1/journal/entries/{id}",
+		Summary:  "Replace a journal entry's slot, analysis and optionally its timestamp",
+		Request:  models.JournalEntryRequest{},
+		Response: models.JournalEntry{},
+		Auth:     true,
+	},
+	{
+		ID:      "deleteJournalEntry",
+		Method:  http.MethodDelete,
+		Path:    "/v1/journal/entries/{id}",
+		Summary: "Delete a journal entry",
+		Auth:    true,
+	},
+	{
+		ID:       "getJournalDay",
+		Method:   http.MethodGet,
+		Path:     "/v1/journal/days/{date}",
+		Summary:  "Total a day's journal entries against the full-day targets",
+		Response: models.DailyTotals{},
+		Auth:     true,
+	},
+	{
+		ID:       "getJournalTrends",
+		Method:   http.MethodGet,
+		Path:     "/v1/journal/trends",
+		Summary:  "Rolling 7- and 30-day nutrient averages, days below target and chronic deficiencies",
+		Response: models.NutrientTrends{},
+		Query:    map[string]string{"date": "last day of the windows (YYYY-MM-DD), default today"},
+		Auth:     true,
+	},
+
+	// Operations
+	{
+		ID:       "health",
+		Method:   http.MethodGet,
+		Path:     "/healthz",
+		Summary:  "Liveness: the process is up and serving",
+		Response: models.HealthStatus{},
+	},
+	{
+		ID:       "ready",
+		Method:   http.MethodGet,
+		Path:     "/readyz",
+		Summary:  "Readiness: dataset loaded, store open and upstream credentials present; 503 otherwise",
+		Response: models.HealthStatus{},
+	},
+	{
+		ID:       "getConfig",
+		Method:   http.MethodGet,
+		Path:     "/v1/admin/config",
+		Summary:  "Effective configuration, secrets redacted",
+		Response: config.Config{},
+		Admin:    true,
+	},
+}
+
+// Unversioned routes kept for clients deployed before /v1
+var legacyRoutes = map[string]string{
+	"/process-food":        "analyzeMeal",
+	"/process-food/batch":  "analyzeMealBatch",
+	"/process-food/stream": "analyzeMealStream",
+	"/fetch-nutrient-data": "lookupFood",
+}
+
+/*=================================================================================================*/
+
+// Guards wrap the handlers of endpoints marked Auth or Admin
+type Guards struct {
+	RequireUser  func(http.Handler) http.Handler
+	RequireAdmin func(http.Handler) http.Handler
+}
+
+// NewHandler routes every endpoint to its handler by ID and serves the OpenAPI document
+func NewHandler(handlers map[string]http.HandlerFunc, guards Guards) (http.Handler, error) {
+	document, err := MarshalDocument(Endpoints)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	guarded := make(map[string]http.Handler, len(Endpoints))
+	for _, endpoint := range Endpoints {
+		handler, ok := handlers[endpoint.ID]
+		if !ok {
+			return nil, fmt.Errorf("api: no handler for %s %s (%s)", endpoint.Method, endpoint.Path, endpoint.ID)
+		}
+		switch {
+		case endpoint.Auth:
+			guarded[endpoint.ID] = guards.RequireUser(handler)
+		case endpoint.Admin:
+			guarded[endpoint.ID] = guards.RequireAdmin(handler)
+		default:
+			guarded[endpoint.ID] = handler
+		}
+		mux.Handle(endpoint.Method+" "+endpoint.Path, guarded[endpoint.ID])
+	}
+	if len(handlers) != len(Endpoints) {
+		return nil, fmt.Errorf("api: %d handlers for %d documented endpoints", len(handlers), len(Endpoints))
+	}
+	// Same guard as the /v1 route they alias
+	for path, id := range legacyRoutes {
+		mux.Handle(path, guarded[id])
+	}
+
+	mux.HandleFunc("GET /"+Version+"/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(document)
+	})
+	return mux, nil
+}
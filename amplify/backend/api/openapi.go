@@ -0,0 +1,242 @@
+// The-Nutrimancers-Codex/amplify/backend/api/openapi.go
+package api
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/models"
+)
+
+// Schema is a JSON object; maps marshal with sorted keys, so documents are stable
+type Schema = map[string]any
+
+var pathParameter = regexp.MustCompile(`\{([^}]+)\}`)
+
+// Document builds the OpenAPI 3 document for the endpoints from their Go types
+func Document(endpoints []Endpoint) (Schema, error) {
+	builder := &schemaBuilder{components: Schema{}, names: map[reflect.Type]string{}}
+	errorSchema := builder.schemaFor(reflect.TypeOf(models.ErrorResponse{}))
+
+	paths := Schema{}
+	for _, endpoint := range endpoints {
+		operation := Schema{
+			"operationId": endpoint.ID,
+			"summary":     endpoint.Summary,
+			"responses": Schema{
+				"default": Schema{
+					"description": "Error",
+					"content":     Schema{"application/json": Schema{"schema": errorSchema}},
+				},
+			},
+		}
+		if endpoint.Request != nil {
+			operation["requestBody"] = Schema{
+				"required": true,
+				"content":  Schema{"application/json": Schema{"schema": builder.schemaFor(reflect.TypeOf(endpoint.Request))}},
+			}
+		}
+		responses := operation["responses"].(Schema)
+		status := endpoint.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		if len(endpoint.Events) > 0 {
+			var names []string
+			var schemas []Schema
+			for _, event := range endpoint.Events {
+				schema := builder.schemaFor(reflect.TypeOf(event.Data))
+				names = append(names, fmt.Sprintf("`%s` (%s)", event.Name, reflect.TypeOf(event.Data).Name()))
+				schemas = append(schemas, schema)
+			}
+			responses[strconv.Itoa(status)] = Schema{
+				"description": "Server-Sent Events, each with JSON data: " + strings.Join(names, ", "),
+				"content":     Schema{"text/event-stream": Schema{"schema": Schema{"oneOf": schemas}}},
+			}
+		} else if endpoint.Response != nil {
+			responses[strconv.Itoa(status)] = Schema{
+				"description": http.StatusText(status),
+				"content":     Schema{"application/json": Schema{"schema": builder.schemaFor(reflect.TypeOf(endpoint.Response))}},
+			}
+		} else {
+			responses[strconv.Itoa(http.StatusNoContent)] = Schema{"description": "No Content"}
+		}
+
+		var parameters []Schema
+		for _, match := range pathParameter.FindAllStringSubmatch(endpoint.Path, -1) {
+			parameters = append(parameters, Schema{
+				"name": match[1], "in": "path", "required": true, "schema": Schema{"type": "string"},
+			})
+		}
+		for _, name := range sortedKeys(endpoint.Query) {
+			parameters = append(parameters, Schema{
+				"name": name, "in": "query", "description": endpoint.Query[name], "schema": Schema{"type": "string"},
+			})
+		}
+		if parameters != nil {
+			operation["parameters"] = parameters
+		}
+		if endpoint.Auth {
+			operation["security"] = []Schema{{"bearerAuth": []string{}}}
+		}
+		if endpoint.Admin {
+			operation["security"] = []Schema{{"adminToken": []string{}}}
+		}
+
+		item, _ := paths[endpoint.Path].(Schema)
+		if item == nil {
+			item = Schema{}
+			paths[endpoint.Path] = item
+		}
+		method := strings.ToLower(endpoint.Method)
+		if _, exists := item[method]; exists {
+			return nil, fmt.Errorf("api: %s %s documented twice", endpoint.Method, endpoint.Path)
+		}
+		item[method] = operation
+	}
+
+	return Schema{
+		"openapi": "3.0.3",
+		"info": Schema{
+			"title":   "The Nutrimancer's Codex API",
+			"version": Version,
+		},
+		"paths": paths,
+		"components": Schema{
+			"schemas": builder.components,
+			"securitySchemes": Schema{
+				"bearerAuth": Schema{"type": "http", "scheme": "bearer", "description": "Opaque token from POST /v1/sessions"},
+				"adminToken": Schema{"type": "http", "scheme": "bearer", "description": "The admin.token configured on the server"},
+			},
+		},
+	}, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// MarshalDocument is Document as indented JSON - the form served and checked in
+func MarshalDocument(endpoints []Endpoint) ([]byte, error) {
+	document, err := Document(endpoints)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+/*=================================================================================================*/
+
+// Named structs become components and are referenced; everything else is inlined
+type schemaBuilder struct {
+	components Schema
+	names      map[reflect.Type]string
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+func (b *schemaBuilder) schemaFor(t reflect.Type) Schema {
+	switch {
+	case t == timeType:
+		return Schema{"type": "string", "format": "date-time"}
+	case t.Implements(textMarshalerType):
+		return Schema{"type": "string"} // e.g. config.Duration
+	case t.Kind() == reflect.Pointer:
+		schema := b.schemaFor(t.Elem())
+		if _, isRef := schema["$ref"]; isRef {
+			return Schema{"allOf": []Schema{schema}, "nullable": true}
+		}
+		schema["nullable"] = true
+		return schema
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return Schema{"type": "array", "items": b.schemaFor(t.Elem())}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": b.schemaFor(t.Elem())}
+	case reflect.Struct:
+		return b.structRef(t)
+	default:
+		return Schema{} // any value
+	}
+}
+
+func (b *schemaBuilder) structRef(t reflect.Type) Schema {
+	name, seen := b.names[t]
+	if !seen {
+		name = b.componentName(t)
+		b.names[t] = name
+		b.components[name] = Schema{} // placeholder for recursive types
+		b.components[name] = b.structSchema(t)
+	}
+	return Schema{"$ref": "#/components/schemas/" + name}
+}
+
+// Type name, prefixed with its package when two packages share a name
+func (b *schemaBuilder) componentName(t reflect.Type) string {
+	name := t.Name()
+	if _, taken := b.components[name]; taken || name == "" {
+		pkg := t.PkgPath()
+		pkg = pkg[strings.LastIndex(pkg, "/")+1:]
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+	return name
+}
+
+func (b *schemaBuilder) structSchema(t reflect.Type) Schema {
+	properties := Schema{}
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = b.schemaFor(field.Type)
+		if !strings.Contains(options, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	schema := Schema{"type": "object", "properties": properties}
+	if required != nil {
+		schema["required"] = required
+	}
+	return schema
+}
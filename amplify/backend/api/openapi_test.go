@@ -0,0 +1,37 @@
+// The-Nutrimancers-Codex/amplify/backend/api/openapi_test.go
+package api
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+// The checked-in contract must match the Go types; regenerate with go run ./cmd/openapi
+func TestGeneratedFilesMatchEndpoints(t *testing.T) {
+	spec, err := MarshalDocument(Endpoints)
+	if err != nil {
+		t.Fatalf("MarshalDocument: %v", err)
+	}
+	document, err := Document(Endpoints)
+	if err != nil {
+		t.Fatalf("Document: %v", err)
+	}
+
+	files := []struct {
+		path string
+		want []byte
+	}{
+		{"../openapi.json", spec},
+		{"../../../frontend/src/services/api.ts", TypeScript(document)},
+	}
+	for _, file := range files {
+		got, err := os.ReadFile(file.path)
+		if err != nil {
+			t.Fatalf("reading %s: %v", file.path, err)
+		}
+		if !bytes.Equal(got, file.want) {
+			t.Errorf("%s is out of date with api.Endpoints; run go run ./cmd/openapi", file.path)
+		}
+	}
+}
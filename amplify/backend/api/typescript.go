@@ -0,0 +1,92 @@
+// The-Nutrimancers-Codex/amplify/backend/api/typescript.go
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TypeScript renders every component schema of a Document as an exported interface -
+// the frontend's src/services/api.ts
+func TypeScript(document Schema) []byte {
+	schemas := document["components"].(Schema)["schemas"].(Schema)
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var out strings.Builder
+	out.WriteString("// The-Nutrimancers-Codex/frontend/src/services/api.ts\n")
+	out.WriteString("// Generated from the backend's Go types by `go run ./cmd/openapi` - do not edit.\n")
+	for _, name := range names {
+		schema := schemas[name].(Schema)
+		fmt.Fprintf(&out, "\nexport interface %s {\n", name)
+
+		required := map[string]bool{}
+		if list, ok := schema["required"].([]string); ok {
+			for _, property := range list {
+				required[property] = true
+			}
+		}
+		properties := schema["properties"].(Schema)
+		keys := make([]string, 0, len(properties))
+		for key := range properties {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			optional := "?"
+			if required[key] {
+				optional = ""
+			}
+			fmt.Fprintf(&out, "  %s%s: %s;\n", quoteKey(key), optional, tsType(properties[key].(Schema)))
+		}
+		out.WriteString("}\n")
+	}
+	return []byte(out.String())
+}
+
+func tsType(schema Schema) string {
+	var t string
+	switch {
+	case schema["$ref"] != nil:
+		ref := schema["$ref"].(string)
+		t = ref[strings.LastIndex(ref, "/")+1:]
+	case schema["allOf"] != nil:
+		t = tsType(schema["allOf"].([]Schema)[0])
+	default:
+		switch schema["type"] {
+		case "string":
+			t = "string"
+		case "integer", "number":
+			t = "number"
+		case "boolean":
+			t = "boolean"
+		case "array":
+			t = tsType(schema["items"].(Schema))
+			if strings.Contains(t, " ") {
+				t = "(" + t + ")"
+			}
+			t += "[]"
+		case "object":
+			t = "{ [key: string]: " + tsType(schema["additionalProperties"].(Schema)) + " }"
+		default:
+			t = "unknown"
+		}
+	}
+	if nullable, _ := schema["nullable"].(bool); nullable {
+		t += " | null"
+	}
+	return t
+}
+
+func quoteKey(key string) string {
+	for _, r := range key {
+		if !(r == '_' || r == '$' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return fmt.Sprintf("%q", key)
+		}
+	}
+	return key
+}
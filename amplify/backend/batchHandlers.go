@@ -0,0 +1,103 @@
+// The-Nutrimancers-Codex/amplify/backend/batchHandlers.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/analysis"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/models"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/utils"
+)
+
+const maxBatchItems = 100
+
+// Analyze many meals with a bounded worker pool; one bad item doesn't fail the batch
+func (s *Server) processFoodBatchHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req models.BatchProcessFoodRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Error decoding request: "+err.Error())
+		return
+	}
+	if len(req.Items) == 0 {
+		utils.RespondWithError(w, http.StatusBadRequest, "At least one item is required")
+		return
+	}
+	if len(req.Items) > maxBatchItems {
+		utils.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("At most %d items per batch, got %d", maxBatchItems, len(req.Items)))
+		return
+	}
+
+	extendWriteDeadline(w)
+	response := models.BatchProcessFoodResponse{Results: s.analyzeMeals(r.Context(), req.Items, s.config.Analysis.BatchWorkers)}
+	for _, result := range response.Results {
+		if result.Error == "" {
+			response.Succeeded++
+		} else {
+			response.Failed++
+		}
+	}
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// Results are written by index, so they come back in input order whatever finishes first.
+// Invalid items are answered up front and never take a worker.
+func (s *Server) analyzeMeals(ctx context.Context, items []models.ProcessFoodRequest, workers int) []models.BatchItemResult {
+	results := make([]models.BatchItemResult, len(items))
+	var valid []int
+	for i, item := range items {
+		if err := analysis.Validate(item); err != nil {
+			results[i] = models.BatchItemResult{Index: i, Error: err.Message, Status: err.Status}
+			continue
+		}
+		valid = append(valid, i)
+	}
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(workers, len(valid)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = s.analyzeBatchItem(ctx, i, items[i])
+			}
+		}()
+	}
+	for _, i := range valid {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
+
+func (s *Server) analyzeBatchItem(ctx context.Context, index int, item models.ProcessFoodRequest) (result models.BatchItemResult) {
+	result.Index = index
+	// A panic in one analysis becomes that item's error instead of taking the server down;
+	// LookupEach recovers its own goroutines' panics the same way
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			result = models.BatchItemResult{Index: index, Error: fmt.Sprintf("analysis failed: %v", recovered), Status: http.StatusInternalServerError}
+		}
+	}()
+
+	response, err := s.analyzer.AnalyzeMeal(ctx, item, nil)
+	if err != nil {
+		result.Error = err.Message
+		result.Status = err.Status
+		return result
+	}
+	result.Result = &response
+	result.Status = http.StatusOK
+	return result
+}
@@ -0,0 +1,212 @@
+// The-Nutrimancers-Codex/amplify/backend/cache/cache.go
+package cache
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/models"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/services"
+	bolt "go.etcd.io/bbolt"
+)
+
+var lookupsBucket = []byte("lookups")
+
+// Options size the cache and say how long answers stay fresh
+type Options struct {
+	Size        int           // entries kept in memory
+	TTL         time.Duration // found foods
+	NotFoundTTL time.Duration // services.ErrFoodNotFound answers; 0 doesn't cache them
+	DB          *bolt.DB      // also keep entries here across restarts; nil for memory only
+
+	// Observe (if set) is told each lookup's outcome: hit, negativeHit or miss, and the
+	// tier that answered: memory, disk or provider
+	Observe func(tier, outcome string)
+}
+
+// Provider is a services.NutrientProvider that remembers another provider's answers, keyed by
+// the normalized ingredient text. Errors other than not found are never cached.
+type Provider struct {
+	next    services.NutrientProvider
+	options Options
+
+	mu      sync.Mutex
+	entries map[string]*list.Element // -> *entry
+	recency *list.List               // front is most recently used
+}
+
+type entry struct {
+	Key      string                 `json:"-"`
+	Result   services.FoodNutrients `json:"result"`
+	NotFound bool                   `json:"notFound"`
+	Expires  time.Time              `json:"expires"`
+}
+
+// New wraps next; with options.DB it creates the lookups bucket and drops expired entries.
+// The caller owns and closes the DB.
+func New(next services.NutrientProvider, options Options) (*Provider, error) {
+	if options.DB != nil {
+		err := options.DB.Update(func(tx *bolt.Tx) error {
+			bucket, err := tx.CreateBucketIfNotExists(lookupsBucket)
+			if err != nil {
+				return err
+			}
+			return prune(bucket, time.Now())
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &Provider{
+		next:    next,
+		options: options,
+		entries: make(map[string]*list.Element),
+		recency: list.New(),
+	}, nil
+}
+
+func (p *Provider) Name() string {
+	return p.next.Name()
+}
+
+func (p *Provider) Ready() error {
+	return p.next.Ready()
+}
+
+func (p *Provider) Lookup(ctx context.Context, ingredient models.Ingredient) (services.FoodNutrients, error) {
+	key := Key(p.next.Name(), ingredient)
+	if cached, tier, ok := p.get(key); ok {
+		if cached.NotFound {
+			p.observe(tier, "negativeHit")
+			return services.FoodNutrients{}, services.ErrFoodNotFound
+		}
+		p.observe(tier, "hit")
+		result := cached.Result
+		result.Amounts = maps.Clone(result.Amounts)
+		return result, nil
+	}
+	p.observe("provider", "miss")
+
+	result, err := p.next.Lookup(ctx, ingredient)
+	switch {
+	case err == nil && p.options.TTL > 0:
+		p.put(&entry{Key: key, Result: result, Expires: time.Now().Add(p.options.TTL)})
+		result.Amounts = maps.Clone(result.Amounts)
+	case errors.Is(err, services.ErrFoodNotFound) && p.options.NotFoundTTL > 0:
+		p.put(&entry{Key: key, NotFound: true, Expires: time.Now().Add(p.options.NotFoundTTL)})
+	}
+	return result, err
+}
+
+// Key is the provider and the ingredient's query, lowercased with whitespace collapsed -
+// "200 g Rice" and "200 g  rice" share an entry, "100 g rice" doesn't
+func Key(provider string, ingredient models.Ingredient) string {
+	return provider + "|" + strings.Join(strings.Fields(strings.ToLower(ingredient.Query())), " ")
+}
+
+/*=================================================================================================*/
+
+// get checks memory, then disk, promoting fresh disk entries into memory
+func (p *Provider) get(key string) (*entry, string, bool) {
+	now := time.Now()
+	p.mu.Lock()
+	if element, ok := p.entries[key]; ok {
+		cached := element.Value.(*entry)
+		if now.Before(cached.Expires) {
+			p.recency.MoveToFront(element)
+			p.mu.Unlock()
+			return cached, "memory", true
+		}
+		p.recency.Remove(element)
+		delete(p.entries, key)
+	}
+	p.mu.Unlock()
+
+	if p.options.DB == nil {
+		return nil, "", false
+	}
+	var stored *entry
+	err := p.options.DB.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(lookupsBucket).Get([]byte(key))
+		if data == nil {
+			return nil
+		}
+		stored = &entry{Key: key}
+		return json.Unmarshal(data, stored)
+	})
+	if err != nil {
+		log.Printf("lookup cache: reading %q: %v", key, err)
+		return nil, "", false
+	}
+	if stored == nil || !now.Before(stored.Expires) {
+		return nil, "", false
+	}
+	p.remember(stored)
+	return stored, "disk", true
+}
+
+func (p *Provider) put(e *entry) {
+	p.remember(e)
+	if p.options.DB == nil {
+		return
+	}
+	data, err := json.Marshal(e)
+	if err == nil {
+		err = p.options.DB.Update(func(tx *bolt.Tx) error {
+			return tx.Bucket(lookupsBucket).Put([]byte(e.Key), data)
+		})
+	}
+	if err != nil {
+		log.Printf("lookup cache: writing %q: %v", e.Key, err)
+	}
+}
+
+// remember adds e to memory, evicting the least recently used entries past Size
+func (p *Provider) remember(e *entry) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if element, ok := p.entries[e.Key]; ok {
+		element.Value = e
+		p.recency.MoveToFront(element)
+		return
+	}
+	p.entries[e.Key] = p.recency.PushFront(e)
+	for p.recency.Len() > p.options.Size {
+		oldest := p.recency.Back()
+		p.recency.Remove(oldest)
+		delete(p.entries, oldest.Value.(*entry).Key)
+	}
+}
+
+func (p *Provider) observe(tier, outcome string) {
+	if p.options.Observe != nil {
+		p.options.Observe(tier, outcome)
+	}
+}
+
+func prune(bucket *bolt.Bucket, now time.Time) error {
+	var expired [][]byte
+	err := bucket.ForEach(func(key, data []byte) error {
+		var stored entry
+		if err := json.Unmarshal(data, &stored); err != nil || !now.Before(stored.Expires) {
+			expired = append(expired, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, key := range expired {
+		if err := bucket.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+// The-Nutrimancers-Codex/amplify/backend/cache/cache_test.go
+package cache
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/models"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/services"
+	bolt "go.etcd.io/bbolt"
+)
+
+// countingProvider finds every food but "unobtainium" and counts the lookups that reach it
+type countingProvider struct {
+	calls map[string]int
+}
+
+func (p *countingProvider) Name() string {
+	return "counting"
+}
+
+func (p *countingProvider) Ready() error {
+	return nil
+}
+
+func (p *countingProvider) Lookup(ctx context.Context, ingredient models.Ingredient) (services.FoodNutrients, error) {
+	p.calls[ingredient.Food]++
+	if ingredient.Food == "unobtainium" {
+		return services.FoodNutrients{}, services.ErrFoodNotFound
+	}
+	return services.FoodNutrients{
+		Amounts: map[string]float64{"Iron": 1},
+		Portion: ingredient.Portion(),
+		Source:  models.FoodSource{Provider: "counting", Description: ingredient.Food},
+	}, nil
+}
+
+// outcomes records what Observe was told, as "tier outcome"
+type outcomes []string
+
+func (o *outcomes) observe(tier, outcome string) {
+	*o = append(*o, tier+" "+outcome)
+}
+
+func (o *outcomes) last() string {
+	if len(*o) == 0 {
+		return ""
+	}
+	return (*o)[len(*o)-1]
+}
+
+func newTestCache(t *testing.T, options Options) (*Provider, *countingProvider, *outcomes) {
+	t.Helper()
+	next := &countingProvider{calls: make(map[string]int)}
+	seen := &outcomes{}
+	options.Observe = seen.observe
+	cached, err := New(next, options)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return cached, next, seen
+}
+
+func lookup(t *testing.T, cached *Provider, food string) error {
+	t.Helper()
+	_, err := cached.Lookup(context.Background(), models.Ingredient{Food: food, Quantity: 1})
+	if err != nil && !errors.Is(err, services.ErrFoodNotFound) {
+		t.Fatalf("Lookup(%s): %v", food, err)
+	}
+	return err
+}
+
+/*=================================================================================================*/
+
+func TestLeastRecentlyUsedEviction(t *testing.T) {
+	cached, next, _ := newTestCache(t, Options{Size: 2, TTL: time.Hour})
+
+	lookup(t, cached, "egg")
+	lookup(t, cached, "rice")
+	lookup(t, cached, "egg") // rice is now the least recently used
+	lookup(t, cached, "kale")
+	lookup(t, cached, "egg")
+	lookup(t, cached, "rice")
+
+	want := map[string]int{"egg": 1, "kale": 1, "rice": 2}
+	for food, calls := range want {
+		if next.calls[food] != calls {
+			t.Errorf("%s reached the provider %d times, want %d", food, next.calls[food], calls)
+		}
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	cached, next, seen := newTestCache(t, Options{Size: 10, TTL: 20 * time.Millisecond, NotFoundTTL: 20 * time.Millisecond})
+
+	lookup(t, cached, "egg")
+	if err := lookup(t, cached, "unobtainium"); !errors.Is(err, services.ErrFoodNotFound) {
+		t.Fatalf("Lookup(unobtainium) = %v, want ErrFoodNotFound", err)
+	}
+
+	lookup(t, cached, "egg")
+	if seen.last() != "memory hit" {
+		t.Errorf("fresh found food: %q, want a memory hit", seen.last())
+	}
+	if err := lookup(t, cached, "unobtainium"); !errors.Is(err, services.ErrFoodNotFound) || seen.last() != "memory negativeHit" {
+		t.Errorf("fresh not-found food: %v, %q; want ErrFoodNotFound from a memory negativeHit", err, seen.last())
+	}
+	if next.calls["egg"] != 1 || next.calls["unobtainium"] != 1 {
+		t.Errorf("provider calls = %v, want one each while fresh", next.calls)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	lookup(t, cached, "egg")
+	lookup(t, cached, "unobtainium")
+	if next.calls["egg"] != 2 || next.calls["unobtainium"] != 2 {
+		t.Errorf("provider calls = %v, want a second lookup of each once expired", next.calls)
+	}
+}
+
+func TestNotFoundNotCachedWithoutTTL(t *testing.T) {
+	cached, next, _ := newTestCache(t, Options{Size: 10, TTL: time.Hour})
+
+	lookup(t, cached, "unobtainium")
+	lookup(t, cached, "unobtainium")
+	if next.calls["unobtainium"] != 2 {
+		t.Errorf("provider calls = %d, want every not-found lookup to reach it with notFoundTtl 0", next.calls["unobtainium"])
+	}
+}
+
+func TestPersistsAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.db")
+	open := func() *bolt.DB {
+		db, err := bolt.Open(path, 0o600, nil)
+		if err != nil {
+			t.Fatalf("opening %s: %v", path, err)
+		}
+		return db
+	}
+
+	db := open()
+	cached, _, _ := newTestCache(t, Options{Size: 10, TTL: time.Hour, NotFoundTTL: time.Hour, DB: db})
+	lookup(t, cached, "egg")
+	lookup(t, cached, "unobtainium")
+	db.Close()
+
+	db = open()
+	defer db.Close()
+	cached, next, seen := newTestCache(t, Options{Size: 10, TTL: time.Hour, NotFoundTTL: time.Hour, DB: db})
+
+	result, err := cached.Lookup(context.Background(), models.Ingredient{Food: "egg", Quantity: 1})
+	if err != nil || result.Amounts["Iron"] != 1 || result.Source.Description != "egg" {
+		t.Errorf("egg after reopening = %+v, %v; want the stored answer", result, err)
+	}
+	if seen.last() != "disk hit" {
+		t.Errorf("egg after reopening: %q, want a disk hit", seen.last())
+	}
+	lookup(t, cached, "egg")
+	if seen.last() != "memory hit" {
+		t.Errorf("egg read from disk again: %q, want it promoted to memory", seen.last())
+	}
+	if err := lookup(t, cached, "unobtainium"); !errors.Is(err, services.ErrFoodNotFound) || seen.last() != "disk negativeHit" {
+		t.Errorf("unobtainium after reopening: %v, %q; want a disk negativeHit", err, seen.last())
+	}
+	if len(next.calls) != 0 {
+		t.Errorf("provider calls after reopening = %v, want none", next.calls)
+	}
+}
+
+func TestPrunesExpiredOnOpen(t *testing.T) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "cache.db"), 0o600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	cached, _, _ := newTestCache(t, Options{Size: 10, TTL: 10 * time.Millisecond, DB: db})
+	lookup(t, cached, "egg")
+	time.Sleep(20 * time.Millisecond)
+	newTestCache(t, Options{Size: 10, TTL: time.Hour, DB: db})
+
+	db.View(func(tx *bolt.Tx) error {
+		if n := tx.Bucket(lookupsBucket).Stats().KeyN; n != 0 {
+			t.Errorf("%d entries left after reopening, want the expired one pruned", n)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,59 @@
+// The-Nutrimancers-Codex/amplify/backend/cmd/openapi/main.go
+//
+// Writes the OpenAPI document and the frontend's TypeScript types from the Go types.
+// With -check it writes nothing and exits non-zero when either checked-in file has drifted.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/api"
+)
+
+func main() {
+	specPath := flag.String("spec", "openapi.json", "OpenAPI document to write or check")
+	tsPath := flag.String("ts", "../../frontend/src/services/api.ts", "TypeScript types to write or check")
+	check := flag.Bool("check", false, "fail if the files differ from the Go types instead of writing them")
+	flag.Parse()
+
+	spec, err := api.MarshalDocument(api.Endpoints)
+	if err != nil {
+		log.Fatal("Error building OpenAPI document:", err)
+	}
+	document, err := api.Document(api.Endpoints)
+	if err != nil {
+		log.Fatal("Error building OpenAPI document:", err)
+	}
+	types := api.TypeScript(document)
+
+	outputs := []struct {
+		path string
+		data []byte
+	}{
+		{*specPath, spec},
+		{*tsPath, types},
+	}
+
+	drifted := false
+	for _, output := range outputs {
+		if *check {
+			current, err := os.ReadFile(output.path)
+			if err != nil || !bytes.Equal(current, output.data) {
+				fmt.Fprintf(os.Stderr, "%s is out of date with the Go types; run go run ./cmd/openapi\n", output.path)
+				drifted = true
+			}
+			continue
+		}
+		if err := os.WriteFile(output.path, output.data, 0o644); err != nil {
+			log.Fatal("Error writing ", output.path, ": ", err)
+		}
+		fmt.Println("Wrote", output.path)
+	}
+	if drifted {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,210 @@
+// The-Nutrimancers-Codex/amplify/backend/config/config.go
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is every deployment setting. Load layers it: Default, then the YAML file,
+// then environment variables, then flags - staging, production and local runs differ only here.
+type Config struct {
+	Server      Server      `yaml:"server" json:"server"`
+	Data        Data        `yaml:"data" json:"data"`
+	Analysis    Analysis    `yaml:"analysis" json:"analysis"`
+	Provider    Provider    `yaml:"provider" json:"provider"`
+	Cache       Cache       `yaml:"cache" json:"cache"`
+	Gemini      Gemini      `yaml:"gemini" json:"gemini"`
+	Nutritionix Nutritionix `yaml:"nutritionix" json:"nutritionix"`
+	FDC         FDC         `yaml:"fdc" json:"fdc"`
+	Admin       Admin       `yaml:"admin" json:"admin"`
+}
+
+type Server struct {
+	Addr            string   `yaml:"addr" json:"addr"`
+	AllowedOrigins  []string `yaml:"allowedOrigins" json:"allowedOrigins"` // CORS
+	ShutdownTimeout Duration `yaml:"shutdownTimeout" json:"shutdownTimeout"`
+}
+
+type Data struct {
+	DatasetPath    string `yaml:"datasetPath" json:"datasetPath"`
+	ThresholdsPath string `yaml:"thresholdsPath" json:"thresholdsPath"` // optional file
+	DatabasePath   string `yaml:"databasePath" json:"databasePath"`
+}
+
+type Analysis struct {
+	TopN             int     `yaml:"topN" json:"topN"`                         // recommendations per list
+	DefaultThreshold float64 `yaml:"defaultThreshold" json:"defaultThreshold"` // overrides the thresholds file's default when > 0
+	BatchWorkers     int     `yaml:"batchWorkers" json:"batchWorkers"`         // meals of a batch analyzed at once
+}
+
+// ProviderNames are the provider.name and provider.fallback values services.NewNutrientProvider builds
+var ProviderNames = []string{"fdc", "nutritionix", "offline"}
+
+// Provider picks the nutrient lookup backend, see services.NewNutrientProvider
+type Provider struct {
+	Name          string  `yaml:"name" json:"name"`
+	Fallback      string  `yaml:"fallback" json:"fallback"`           // answers while name is out of quota or unconfigured; empty for none
+	MinConfidence float64 `yaml:"minConfidence" json:"minConfidence"` // offline provider's weakest accepted match, 0-1
+	Concurrency   int     `yaml:"concurrency" json:"concurrency"`     // a meal's ingredients looked up at once
+}
+
+// Cache keeps nutrient provider answers, see cache.Provider
+type Cache struct {
+	Size        int      `yaml:"size" json:"size"`               // entries in memory; 0 disables the cache
+	TTL         Duration `yaml:"ttl" json:"ttl"`                 // found foods
+	NotFoundTTL Duration `yaml:"notFoundTtl" json:"notFoundTtl"` // "no foods found"; 0 doesn't cache those
+	Persist     bool     `yaml:"persist" json:"persist"`         // also keep entries in data.databasePath across restarts
+}
+
+type Gemini struct {
+	Endpoint string `yaml:"endpoint" json:"endpoint"`
+	Model    string `yaml:"model" json:"model"`
+	APIKey   string `yaml:"apiKey" json:"apiKey" secret:"true"`
+}
+
+type Nutritionix struct {
+	Endpoint string `yaml:"endpoint" json:"endpoint"`
+	AppID    string `yaml:"appId" json:"appId"`
+	AppKey   string `yaml:"appKey" json:"appKey" secret:"true"`
+}
+
+// FDC is USDA FoodData Central
+type FDC struct {
+	Endpoint  string   `yaml:"endpoint" json:"endpoint"`
+	APIKey    string   `yaml:"apiKey" json:"apiKey" secret:"true"`
+	DataTypes []string `yaml:"dataTypes" json:"dataTypes"` // search filter; empty searches every data type
+}
+
+type Admin struct {
+	Token string `yaml:"token" json:"token" secret:"true"` // bearer token for /v1/admin; empty disables it
+}
+
+// Duration reads and writes as "30s", "2m" in both YAML and JSON
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+/*=================================================================================================*/
+
+// Default is the local-development setup the server always shipped with. The upstream values
+// match the services package's Default* constants; config doesn't import the code it configures.
+func Default() Config {
+	return Config{
+		Server: Server{
+			Addr: ":5000",
+			AllowedOrigins: []string{
+				"http://localhost:5173",
+				"https://main.d27vjqcvk3diok.amplifyapp.com",
+				"http://Nutrimancer-env.eba-mhnjc34h.us-east-1.elasticbeanstalk.com",
+			},
+			ShutdownTimeout: Duration(30 * time.Second),
+		},
+		Data: Data{
+			DatasetPath:    "machinist/dataset.csv",
+			ThresholdsPath: "thresholds.json",
+			DatabasePath:   "nutrimancer.db",
+		},
+		Analysis: Analysis{TopN: 5, BatchWorkers: 4},
+		Provider: Provider{
+			Name:          "nutritionix",
+			Fallback:      "offline",
+			MinConfidence: 0.35,
+			Concurrency:   4,
+		},
+		Cache: Cache{
+			Size:        1000,
+			TTL:         Duration(7 * 24 * time.Hour),
+			NotFoundTTL: Duration(time.Hour),
+			Persist:     true,
+		},
+		Gemini: Gemini{
+			Endpoint: "https://generativelanguage.googleapis.com/v1beta",
+			Model:    "gemini-1.5-flash-latest",
+		},
+		Nutritionix: Nutritionix{
+			Endpoint: "https://trackapi.nutritionix.com/v2/natural/nutrients",
+		},
+		FDC: FDC{
+			Endpoint:  "https://api.nal.usda.gov/fdc/v1",
+			DataTypes: []string{"Foundation", "SR Legacy"},
+		},
+	}
+}
+
+// ReadFile layers a YAML file over c; keys the file leaves out keep their current values
+func (c *Config) ReadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)                                            // a misspelt key is an error, not a silently ignored setting
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) { // io.EOF: empty file
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	return nil
+}
+
+// Validate checks everything the server needs before it starts. Missing credentials
+// aren't errors - the server runs without them and reports itself not ready.
+func (c Config) Validate() error {
+	var problems []string
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(c.Server.Addr != "", "server.addr is required")
+	check(len(c.Server.AllowedOrigins) > 0, "server.allowedOrigins needs at least one origin")
+	for _, origin := range c.Server.AllowedOrigins {
+		check(origin == "*" || isAbsoluteURL(origin), "server.allowedOrigins: %q is not an origin URL", origin)
+	}
+	check(c.Server.ShutdownTimeout > 0, "server.shutdownTimeout must be positive")
+	check(c.Data.DatasetPath != "", "data.datasetPath is required")
+	check(c.Data.DatabasePath != "", "data.databasePath is required")
+	check(c.Analysis.TopN >= 1 && c.Analysis.TopN <= 50, "analysis.topN must be 1-50, got %d", c.Analysis.TopN)
+	check(c.Analysis.DefaultThreshold >= 0 && c.Analysis.DefaultThreshold <= 1, "analysis.defaultThreshold must be 0-1, got %g", c.Analysis.DefaultThreshold)
+	check(c.Analysis.BatchWorkers >= 1 && c.Analysis.BatchWorkers <= 32, "analysis.batchWorkers must be 1-32, got %d", c.Analysis.BatchWorkers)
+	check(slices.Contains(ProviderNames, c.Provider.Name), "provider.name must be one of %v, got %q", ProviderNames, c.Provider.Name)
+	check(c.Provider.Fallback == "" || slices.Contains(ProviderNames, c.Provider.Fallback), "provider.fallback must be empty or one of %v, got %q", ProviderNames, c.Provider.Fallback)
+	check(c.Provider.MinConfidence >= 0 && c.Provider.MinConfidence <= 1, "provider.minConfidence must be 0-1, got %g", c.Provider.MinConfidence)
+	check(c.Provider.Concurrency >= 1 && c.Provider.Concurrency <= 32, "provider.concurrency must be 1-32, got %d", c.Provider.Concurrency)
+	check(c.Cache.Size >= 0, "cache.size must not be negative, got %d", c.Cache.Size)
+	check(c.Cache.TTL >= 0 && c.Cache.NotFoundTTL >= 0, "cache.ttl and cache.notFoundTtl must not be negative")
+	check(isAbsoluteURL(c.Gemini.Endpoint), "gemini.endpoint: %q is not an absolute URL", c.Gemini.Endpoint)
+	check(c.Gemini.Model != "", "gemini.model is required")
+	check(isAbsoluteURL(c.Nutritionix.Endpoint), "nutritionix.endpoint: %q is not an absolute URL", c.Nutritionix.Endpoint)
+	check(isAbsoluteURL(c.FDC.Endpoint), "fdc.endpoint: %q is not an absolute URL", c.FDC.Endpoint)
+
+	if problems != nil {
+		return fmt.Errorf("invalid config: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func isAbsoluteURL(raw string) bool {
+	parsed, err := url.Parse(raw)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
@@ -0,0 +1,76 @@
+// The-Nutrimancers-Codex/amplify/backend/config/config_test.go
+package config
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/services"
+)
+
+// config repeats these rather than importing services; they mustn't drift apart
+func TestDefaultsMatchServices(t *testing.T) {
+	c := Default()
+	if !slices.Equal(ProviderNames, services.ProviderNames()) {
+		t.Errorf("ProviderNames = %v, services builds %v", ProviderNames, services.ProviderNames())
+	}
+	checks := []struct {
+		name      string
+		got, want any
+	}{
+		{"provider.minConfidence", c.Provider.MinConfidence, services.DefaultDatasetMinConfidence},
+		{"provider.concurrency", c.Provider.Concurrency, services.DefaultLookupConcurrency},
+		{"gemini.endpoint", c.Gemini.Endpoint, services.DefaultGeminiEndpoint},
+		{"gemini.model", c.Gemini.Model, services.DefaultGeminiModel},
+		{"nutritionix.endpoint", c.Nutritionix.Endpoint, services.DefaultNutritionixEndpoint},
+		{"fdc.endpoint", c.FDC.Endpoint, services.DefaultFDCEndpoint},
+	}
+	for _, check := range checks {
+		if check.got != check.want {
+			t.Errorf("%s = %v, services default is %v", check.name, check.got, check.want)
+		}
+	}
+	if !slices.Equal(c.FDC.DataTypes, services.DefaultFDCDataTypes) {
+		t.Errorf("fdc.dataTypes = %v, services default is %v", c.FDC.DataTypes, services.DefaultFDCDataTypes)
+	}
+}
+
+func TestLoadEnvironment(t *testing.T) {
+	env := map[string]string{
+		"NUTRIMANCER_CONFIG":            "testdata/missing.yaml",
+		"NUTRIMANCER_PROVIDER_FALLBACK": "", // set but empty: no fallback
+		"NUTRIMANCER_TOP_N":             "7",
+		"PORT":                          "", // set but empty: keep the configured address
+	}
+	lookupEnv := func(name string) (string, bool) {
+		value, set := env[name]
+		return value, set
+	}
+
+	if _, err := Load(nil, lookupEnv); err == nil {
+		t.Fatal("Load with a missing $NUTRIMANCER_CONFIG succeeded, want an error")
+	}
+	env["NUTRIMANCER_CONFIG"] = ""
+
+	c, err := Load([]string{"-top-n", "9"}, lookupEnv)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if c.Provider.Fallback != "" {
+		t.Errorf("provider.fallback = %q, want the empty value from the environment", c.Provider.Fallback)
+	}
+	if c.Analysis.TopN != 9 {
+		t.Errorf("analysis.topN = %d, want 9: flags override the environment", c.Analysis.TopN)
+	}
+	if c.Server.Addr != Default().Server.Addr {
+		t.Errorf("server.addr = %q, want the default for an empty PORT", c.Server.Addr)
+	}
+	if c.Provider.Name != Default().Provider.Name {
+		t.Errorf("provider.name = %q, want the default for an unset variable", c.Provider.Name)
+	}
+
+	env["PORT"] = "8080"
+	if c, err := Load(nil, lookupEnv); err != nil || c.Server.Addr != ":8080" {
+		t.Errorf("Load with PORT=8080: server.addr = %q, %v; want :8080", c.Server.Addr, err)
+	}
+}
@@ -0,0 +1,196 @@
+// The-Nutrimancers-Codex/amplify/backend/config/load.go
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DefaultPath is read when neither -config nor NUTRIMANCER_CONFIG names a file; it may be absent
+const DefaultPath = "config.yaml"
+
+// One overridable setting. Secrets have no flag so they stay out of process listings.
+type setting struct {
+	env   string
+	flag  string
+	usage string
+	apply func(c *Config, value string) error
+}
+
+var settings = []setting{
+	{"PORT", "", "port to listen on, as assigned by Elastic Beanstalk", func(c *Config, v string) error {
+		if v != "" { // ":" would listen on a random port
+			c.Server.Addr = ":" + v
+		}
+		return nil
+	}},
+	{"NUTRIMANCER_ADDR", "addr", "address to listen on, e.g. :5000", setString(func(c *Config) *string { return &c.Server.Addr })},
+	{"NUTRIMANCER_ALLOWED_ORIGINS", "allowed-origins", "comma-separated CORS origins", func(c *Config, v string) error {
+		c.Server.AllowedOrigins = splitList(v)
+		return nil
+	}},
+	{"NUTRIMANCER_SHUTDOWN_TIMEOUT", "shutdown-timeout", "how long in-flight requests get on shutdown, e.g. 30s", func(c *Config, v string) error {
+		return c.Server.ShutdownTimeout.UnmarshalText([]byte(v))
+	}},
+	{"NUTRIMANCER_DATASET_PATH", "dataset", "USDA food dataset CSV", setString(func(c *Config) *string { return &c.Data.DatasetPath })},
+	{"NUTRIMANCER_THRESHOLDS_PATH", "thresholds", "deficiency thresholds JSON (optional)", setString(func(c *Config) *string { return &c.Data.ThresholdsPath })},
+	{"NUTRIMANCER_DATABASE_PATH", "db", "bbolt file for accounts and the journal", setString(func(c *Config) *string { return &c.Data.DatabasePath })},
+	{"NUTRIMANCER_TOP_N", "top-n", "recommendations per list", func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		c.Analysis.TopN = n
+		return err
+	}},
+	{"NUTRIMANCER_DEFAULT_THRESHOLD", "default-threshold", "default deficiency threshold, 0-1", func(c *Config, v string) error {
+		f, err := strconv.ParseFloat(v, 64)
+		c.Analysis.DefaultThreshold = f
+		return err
+	}},
+	{"NUTRIMANCER_BATCH_WORKERS", "batch-workers", "meals of a batch analyzed at once", func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		c.Analysis.BatchWorkers = n
+		return err
+	}},
+	{"NUTRIMANCER_PROVIDER", "provider", "nutrient lookup backend", setString(func(c *Config) *string { return &c.Provider.Name })},
+	{"NUTRIMANCER_PROVIDER_FALLBACK", "provider-fallback", "nutrient lookup backend while the first is out of quota, empty for none", setString(func(c *Config) *string { return &c.Provider.Fallback })},
+	{"NUTRIMANCER_MIN_CONFIDENCE", "min-confidence", "weakest offline match accepted, 0-1", func(c *Config, v string) error {
+		f, err := strconv.ParseFloat(v, 64)
+		c.Provider.MinConfidence = f
+		return err
+	}},
+	{"NUTRIMANCER_LOOKUP_CONCURRENCY", "lookup-concurrency", "ingredients looked up at once per meal", func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		c.Provider.Concurrency = n
+		return err
+	}},
+	{"NUTRIMANCER_CACHE_SIZE", "cache-size", "nutrient lookups cached in memory, 0 disables the cache", func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		c.Cache.Size = n
+		return err
+	}},
+	{"NUTRIMANCER_CACHE_TTL", "cache-ttl", "how long a cached lookup stays fresh, e.g. 168h", func(c *Config, v string) error {
+		return c.Cache.TTL.UnmarshalText([]byte(v))
+	}},
+	{"NUTRIMANCER_CACHE_NOT_FOUND_TTL", "cache-not-found-ttl", "how long a cached \"no foods found\" stays fresh, 0 to not cache them", func(c *Config, v string) error {
+		return c.Cache.NotFoundTTL.UnmarshalText([]byte(v))
+	}},
+	{"NUTRIMANCER_CACHE_PERSIST", "cache-persist", "keep cached lookups in the database across restarts", func(c *Config, v string) error {
+		b, err := strconv.ParseBool(v)
+		c.Cache.Persist = b
+		return err
+	}},
+	{"GEMINI_ENDPOINT", "gemini-endpoint", "Gemini API base URL", setString(func(c *Config) *string { return &c.Gemini.Endpoint })},
+	{"GEMINI_MODEL", "gemini-model", "Gemini model name", setString(func(c *Config) *string { return &c.Gemini.Model })},
+	{"API_KEY", "", "", setString(func(c *Config) *string { return &c.Gemini.APIKey })},
+	{"NUTRITIONIX_ENDPOINT", "nutritionix-endpoint", "Nutritionix natural/nutrients URL", setString(func(c *Config) *string { return &c.Nutritionix.Endpoint })},
+	{"NUTRITIONIX_APP_ID", "", "", setString(func(c *Config) *string { return &c.Nutritionix.AppID })},
+	{"NUTRITIONIX_APP_KEY", "", "", setString(func(c *Config) *string { return &c.Nutritionix.AppKey })},
+	{"FDC_ENDPOINT", "fdc-endpoint", "FoodData Central API base URL", setString(func(c *Config) *string { return &c.FDC.Endpoint })},
+	{"FDC_API_KEY", "", "", setString(func(c *Config) *string { return &c.FDC.APIKey })},
+	{"FDC_DATA_TYPES", "fdc-data-types", "comma-separated FoodData Central data types to search", func(c *Config, v string) error {
+		c.FDC.DataTypes = splitList(v)
+		return nil
+	}},
+	{"NUTRIMANCER_ADMIN_TOKEN", "", "", setString(func(c *Config) *string { return &c.Admin.Token })},
+}
+
+func setString(field func(c *Config) *string) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		*field(c) = value
+		return nil
+	}
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+/*=================================================================================================*/
+
+// Load builds the effective config from Default, the config file, the environment (lookupEnv,
+// e.g. os.LookupEnv) and command-line args, in that order, and validates it. A variable that is
+// set applies even when empty, e.g. NUTRIMANCER_PROVIDER_FALLBACK= turns the fallback off;
+// an empty PORT is the exception and keeps the configured address.
+func Load(args []string, lookupEnv func(string) (string, bool)) (Config, error) {
+	flags := flag.NewFlagSet("nutrimancer", flag.ContinueOnError)
+	path := flags.String("config", "", "YAML config file (default $NUTRIMANCER_CONFIG or "+DefaultPath+")")
+	values := make(map[string]*string)
+	for _, s := range settings {
+		if s.flag != "" {
+			values[s.flag] = flags.String(s.flag, "", s.usage+" ($"+s.env+")")
+		}
+	}
+	if err := flags.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	c := Default()
+
+	// File - an explicitly named one must exist
+	explicit := true
+	if *path == "" {
+		*path, _ = lookupEnv("NUTRIMANCER_CONFIG")
+	}
+	if *path == "" {
+		*path, explicit = DefaultPath, false
+	}
+	if err := c.ReadFile(*path); err != nil && (explicit || !errors.Is(err, os.ErrNotExist)) {
+		return Config{}, err
+	}
+
+	// Environment
+	for _, s := range settings {
+		if value, set := lookupEnv(s.env); set {
+			if err := s.apply(&c, value); err != nil {
+				return Config{}, fmt.Errorf("$%s: %v", s.env, err)
+			}
+		}
+	}
+
+	// Flags given on the command line
+	var flagErr error
+	flags.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if s.flag == f.Name && flagErr == nil {
+				if err := s.apply(&c, *values[f.Name]); err != nil {
+					flagErr = fmt.Errorf("-%s: %v", f.Name, err)
+				}
+			}
+		}
+	})
+	if flagErr != nil {
+		return Config{}, flagErr
+	}
+
+	return c, c.Validate()
+}
+
+/*=================================================================================================*/
+
+// Redacted is a copy safe to show: every field tagged secret reads "[redacted]" when set
+func (c Config) Redacted() Config {
+	redact(reflect.ValueOf(&c).Elem())
+	return c
+}
+
+func redact(v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		switch {
+		case field.Kind() == reflect.Struct:
+			redact(field)
+		case v.Type().Field(i).Tag.Get("secret") == "true" && field.String() != "":
+			field.SetString("[redacted]")
+		}
+	}
+}
@@ -0,0 +1,31 @@
+// The-Nutrimancers-Codex/amplify/backend/derived/derived.go
+package derived
+
+// Metrics computed across several nutrients at once
+type Metrics struct {
+	FattyAcids FattyAcidBalance `json:"fattyAcids"`
+	Protein    ProteinQuality   `json:"protein"`
+}
+
+// Compute derives metrics from per-ingredient micronutrient amounts in mg and
+// per-ingredient macros in registry units (Protein in g)
+func Compute(amountsMg map[string]map[string]float64, macros map[string]map[string]float64) Metrics {
+	totalsMg := make(map[string]float64)
+	for _, amounts := range amountsMg {
+		for name, amount := range amounts {
+			totalsMg[name] += amount
+		}
+	}
+	return Metrics{
+		FattyAcids: computeFattyAcidBalance(totalsMg),
+		Protein:    computeProteinQuality(amountsMg, macros),
+	}
+}
+
+// RecommenderWeights turns the metrics into extra recommender targets;
+// positive weights are sought, negative weights are avoided
+func (m Metrics) RecommenderWeights() map[string]float64 {
+	weights := make(map[string]float64)
+	m.FattyAcids.recommenderWeights(weights)
+	return weights
+}
@@ -0,0 +1,65 @@
+// The-Nutrimancers-Codex/amplify/backend/derived/fattyAcids.go
+package derived
+
+const maxOmega6To3Ratio = 4.0 // above 4:1 counts as skewed
+
+type RatioStatus string
+
+const (
+	Balanced RatioStatus = "balanced"
+	Skewed   RatioStatus = "skewed"
+	NoOmega3 RatioStatus = "no omega-3"
+	NoOmegas RatioStatus = "none"
+)
+
+// Omega-6 / omega-3 balance of a meal, from absolute amounts
+type FattyAcidBalance struct {
+	Omega6Mg          float64     `json:"omega6Mg"`       // Linoleic Acid
+	Omega3Mg          float64     `json:"omega3Mg"`       // ALA + EPA + DHA
+	Omega6To3Ratio    *float64    `json:"omega6To3Ratio"` // nil when there is no omega-3
+	RatioStatus       RatioStatus `json:"ratioStatus"`
+	EPAPlusDHAMg      float64     `json:"epaPlusDhaMg"`      // long-chain omega-3
+	PlantOmega3Share  float64     `json:"plantOmega3Share"`  // ALA / omega-3, 0-1
+	MarineOmega3Share float64     `json:"marineOmega3Share"` // (EPA + DHA) / omega-3, 0-1
+}
+
+/*=================================================================================================*/
+
+func computeFattyAcidBalance(totalsMg map[string]float64) FattyAcidBalance {
+	ala := totalsMg["Alpha-Linolenic Acid"]
+	la := totalsMg["Linoleic Acid"]
+	longChain := totalsMg["EPA"] + totalsMg["DHA"]
+
+	balance := FattyAcidBalance{
+		Omega6Mg:     la,
+		Omega3Mg:     ala + longChain,
+		EPAPlusDHAMg: longChain,
+	}
+
+	switch {
+	case balance.Omega3Mg > 0:
+		ratio := la / balance.Omega3Mg
+		balance.Omega6To3Ratio = &ratio
+		balance.PlantOmega3Share = ala / balance.Omega3Mg
+		balance.MarineOmega3Share = longChain / balance.Omega3Mg
+		balance.RatioStatus = Balanced
+		if ratio > maxOmega6To3Ratio {
+			balance.RatioStatus = Skewed
+		}
+	case la > 0:
+		balance.RatioStatus = NoOmega3
+	default:
+		balance.RatioStatus = NoOmegas
+	}
+	return balance
+}
+
+// Skewed ratio -> favour fish (EPA/DHA) and flax (ALA), penalize more omega-6
+func (b FattyAcidBalance) recommenderWeights(weights map[string]float64) {
+	if b.RatioStatus == Skewed || b.RatioStatus == NoOmega3 {
+		weights["Alpha-Linolenic Acid"] = 1.0
+		weights["EPA"] = 1.0
+		weights["DHA"] = 1.0
+		weights["Linoleic Acid"] = -1.0
+	}
+}
@@ -0,0 +1,111 @@
+// The-Nutrimancers-Codex/amplify/backend/derived/fattyAcids_test.go
+package derived
+
+import (
+	"maps"
+	"math"
+	"testing"
+)
+
+func ptr(value float64) *float64 {
+	return &value
+}
+
+// For messages: "<nil>" rather than an address
+func deref(value *float64) any {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
+/*=================================================================================================*/
+
+func TestFattyAcidBalance(t *testing.T) {
+	tests := []struct {
+		name     string
+		totalsMg map[string]float64
+		want     FattyAcidBalance
+	}{
+		{
+			name:     "plant oils, 3:1",
+			totalsMg: map[string]float64{"Linoleic Acid": 3000, "Alpha-Linolenic Acid": 1000},
+			want:     FattyAcidBalance{Omega6Mg: 3000, Omega3Mg: 1000, Omega6To3Ratio: ptr(3), RatioStatus: Balanced, PlantOmega3Share: 1},
+		},
+		{
+			name:     "exactly 4:1 is still balanced",
+			totalsMg: map[string]float64{"Linoleic Acid": 4000, "Alpha-Linolenic Acid": 1000},
+			want:     FattyAcidBalance{Omega6Mg: 4000, Omega3Mg: 1000, Omega6To3Ratio: ptr(4), RatioStatus: Balanced, PlantOmega3Share: 1},
+		},
+		{
+			// 10000 / (1000 + 500 + 500) = 5:1; half the omega-3 is long-chain
+			name:     "skewed despite fish",
+			totalsMg: map[string]float64{"Linoleic Acid": 10000, "Alpha-Linolenic Acid": 1000, "EPA": 500, "DHA": 500},
+			want: FattyAcidBalance{Omega6Mg: 10000, Omega3Mg: 2000, Omega6To3Ratio: ptr(5), RatioStatus: Skewed,
+				EPAPlusDHAMg: 1000, PlantOmega3Share: 0.5, MarineOmega3Share: 0.5},
+		},
+		{
+			name:     "omega-3 only",
+			totalsMg: map[string]float64{"EPA": 300, "DHA": 200},
+			want:     FattyAcidBalance{Omega3Mg: 500, Omega6To3Ratio: ptr(0), RatioStatus: Balanced, EPAPlusDHAMg: 500, MarineOmega3Share: 1},
+		},
+		{
+			name:     "no omega-3: no ratio rather than a division by zero",
+			totalsMg: map[string]float64{"Linoleic Acid": 5000, "Iron": 2},
+			want:     FattyAcidBalance{Omega6Mg: 5000, RatioStatus: NoOmega3},
+		},
+		{
+			name:     "neither",
+			totalsMg: map[string]float64{"Iron": 2},
+			want:     FattyAcidBalance{RatioStatus: NoOmegas},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := computeFattyAcidBalance(test.totalsMg)
+			if (got.Omega6To3Ratio == nil) != (test.want.Omega6To3Ratio == nil) ||
+				(got.Omega6To3Ratio != nil && math.Abs(*got.Omega6To3Ratio-*test.want.Omega6To3Ratio) > 1e-9) {
+				t.Errorf("ratio = %v, want %v", deref(got.Omega6To3Ratio), deref(test.want.Omega6To3Ratio))
+			}
+			got.Omega6To3Ratio, test.want.Omega6To3Ratio = nil, nil
+			if got != test.want {
+				t.Errorf("balance = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFattyAcidRecommenderWeights(t *testing.T) {
+	seek := map[string]float64{"Alpha-Linolenic Acid": 1, "EPA": 1, "DHA": 1, "Linoleic Acid": -1}
+	tests := []struct {
+		status RatioStatus
+		want   map[string]float64
+	}{
+		{Skewed, seek},
+		{NoOmega3, seek},
+		{Balanced, map[string]float64{}},
+		{NoOmegas, map[string]float64{}},
+	}
+	for _, test := range tests {
+		weights := make(map[string]float64)
+		FattyAcidBalance{RatioStatus: test.status}.recommenderWeights(weights)
+		if !maps.Equal(weights, test.want) {
+			t.Errorf("%s: weights = %v, want %v", test.status, weights, test.want)
+		}
+	}
+}
+
+func TestComputeSumsIngredients(t *testing.T) {
+	metrics := Compute(map[string]map[string]float64{
+		"salmon":  {"EPA": 400, "DHA": 1100, "Linoleic Acid": 200},
+		"walnuts": {"Alpha-Linolenic Acid": 2500, "Linoleic Acid": 11000},
+	}, nil)
+	// 11200 / (2500 + 1500) = 2.8:1
+	balance := metrics.FattyAcids
+	if balance.Omega6To3Ratio == nil || math.Abs(*balance.Omega6To3Ratio-2.8) > 1e-9 || balance.RatioStatus != Balanced {
+		t.Errorf("balance = %+v with ratio %v, want 2.8:1 and balanced", balance, deref(balance.Omega6To3Ratio))
+	}
+	if len(metrics.RecommenderWeights()) != 0 {
+		t.Errorf("weights = %v, want none for a balanced meal", metrics.RecommenderWeights())
+	}
+}
@@ -0,0 +1,123 @@
+// The-Nutrimancers-Codex/amplify/backend/derived/proteinQuality.go
+package derived
+
+import "sort"
+
+// ReferencePattern is the WHO/FAO/UNU (2007) adult scoring pattern in mg per g protein.
+// Cysteine and tyrosine aren't tracked, so Methionine uses the methionine-only value and
+// Phenylalanine half of the aromatic (Phe + Tyr) requirement.
+var ReferencePattern = map[string]float64{
+	"Histidine":     15,
+	"Isoleucine":    30,
+	"Leucine":       59,
+	"Lysine":        45,
+	"Methionine":    16,
+	"Phenylalanine": 19,
+	"Threonine":     23,
+	"Tryptophan":    6,
+	"Valine":        39,
+}
+
+const minProteinG = 0.5 // less protein than this isn't scored
+
+// Amino acid score of one ingredient or the whole meal (PDCAAS without digestibility)
+type AminoAcidScore struct {
+	ProteinG          float64            `json:"proteinG"`
+	Score             *float64           `json:"score"`                       // lowest ratio, truncated at 1.0; nil when unscored
+	LimitingAminoAcid string             `json:"limitingAminoAcid,omitempty"` // amino acid with the lowest ratio
+	Ratios            map[string]float64 `json:"ratios"`                      // mg per g protein / reference, untruncated
+	Unreported        []string           `json:"unreported,omitempty"`        // amino acids the provider didn't report
+}
+
+// Meal scores only ingredients that report every reference amino acid; the rest would add
+// protein without its amino acids and pull the score down
+type ProteinQuality struct {
+	Meal          AminoAcidScore            `json:"meal"`
+	PerIngredient map[string]AminoAcidScore `json:"perIngredient"`
+	Coverage      float64                   `json:"coverage"`           // share of the meal's protein behind Meal; 1 when every ingredient has a full profile
+	Unscored      []string                  `json:"unscored,omitempty"` // protein-bearing ingredients left out of Meal
+}
+
+/*=================================================================================================*/
+
+func computeProteinQuality(amountsMg map[string]map[string]float64, macros map[string]map[string]float64) ProteinQuality {
+	quality := ProteinQuality{PerIngredient: make(map[string]AminoAcidScore)}
+
+	mealAminoAcidsMg := make(map[string]float64)
+	mealProteinG, totalProteinG := 0.0, 0.0
+	for ingredient, amounts := range amountsMg {
+		proteinG := macros[ingredient]["Protein"]
+		quality.PerIngredient[ingredient] = scoreAminoAcids(amounts, proteinG)
+		totalProteinG += proteinG
+
+		if !reportsPattern(amounts) {
+			if proteinG > 0 {
+				quality.Unscored = append(quality.Unscored, ingredient)
+			}
+			continue
+		}
+		mealProteinG += proteinG
+		for name := range ReferencePattern {
+			mealAminoAcidsMg[name] += amounts[name]
+		}
+	}
+	sort.Strings(quality.Unscored)
+
+	quality.Meal = scoreAminoAcids(mealAminoAcidsMg, mealProteinG)
+	quality.Coverage = 1
+	if totalProteinG > 0 {
+		quality.Coverage = mealProteinG / totalProteinG
+	}
+	return quality
+}
+
+func reportsPattern(amountsMg map[string]float64) bool {
+	for name := range ReferencePattern {
+		if _, reported := amountsMg[name]; !reported {
+			return false
+		}
+	}
+	return true
+}
+
+func scoreAminoAcids(amountsMg map[string]float64, proteinG float64) AminoAcidScore {
+	score := AminoAcidScore{ProteinG: proteinG, Ratios: make(map[string]float64)}
+	if proteinG < minProteinG {
+		return score
+	}
+
+	lowest := 0.0
+	for _, name := range sortedPatternNames() {
+		amount, reported := amountsMg[name]
+		if !reported {
+			score.Unreported = append(score.Unreported, name)
+			continue
+		}
+		ratio := amount / proteinG / ReferencePattern[name]
+		score.Ratios[name] = ratio
+		if score.LimitingAminoAcid == "" || ratio < lowest {
+			lowest = ratio
+			score.LimitingAminoAcid = name
+		}
+	}
+	if score.LimitingAminoAcid == "" {
+		return score
+	}
+
+	truncated := lowest
+	if truncated > 1.0 {
+		truncated = 1.0
+	}
+	score.Score = &truncated
+	return score
+}
+
+// Stable order so ties pick the same limiting amino acid every time
+func sortedPatternNames() []string {
+	names := make([]string, 0, len(ReferencePattern))
+	for name := range ReferencePattern {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,172 @@
+// The-Nutrimancers-Codex/amplify/backend/derived/proteinQuality_test.go
+package derived
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// profile reports every reference amino acid at factor times the pattern for proteinG grams,
+// with overrides in mg per g protein
+func profile(proteinG, factor float64, overrides map[string]float64) map[string]float64 {
+	amounts := make(map[string]float64)
+	for name, reference := range ReferencePattern {
+		perGram := reference * factor
+		if override, ok := overrides[name]; ok {
+			perGram = override
+		}
+		amounts[name] = perGram * proteinG
+	}
+	return amounts
+}
+
+func withoutAminoAcid(amountsMg map[string]float64, name string) map[string]float64 {
+	delete(amountsMg, name)
+	return amountsMg
+}
+
+func protein(grams float64) map[string]float64 {
+	return map[string]float64{"Protein": grams}
+}
+
+func closeTo(got, want float64) bool {
+	return math.Abs(got-want) < 1e-9
+}
+
+/*=================================================================================================*/
+
+func TestScoreAminoAcids(t *testing.T) {
+	tests := []struct {
+		name           string
+		amountsMg      map[string]float64
+		proteinG       float64
+		wantScore      *float64
+		wantLimiting   string
+		wantUnreported []string
+	}{
+		{
+			// 25 mg lysine per g against 45: 25/45
+			name:         "lysine-limited grain",
+			amountsMg:    profile(10, 1.2, map[string]float64{"Lysine": 25}),
+			proteinG:     10,
+			wantScore:    ptr(25.0 / 45),
+			wantLimiting: "Lysine",
+		},
+		{
+			// Every ratio 2: truncated to 1, ties broken alphabetically
+			name:         "complete protein",
+			amountsMg:    profile(10, 2, nil),
+			proteinG:     10,
+			wantScore:    ptr(1),
+			wantLimiting: "Histidine",
+		},
+		{
+			// Scored from the amino acids that were reported
+			name:           "partial profile",
+			amountsMg:      withoutAminoAcid(profile(4, 0.5, nil), "Tryptophan"),
+			proteinG:       4,
+			wantScore:      ptr(0.5),
+			wantLimiting:   "Histidine",
+			wantUnreported: []string{"Tryptophan"},
+		},
+		{
+			name:      "too little protein to score",
+			amountsMg: profile(0.4, 1, nil),
+			proteinG:  0.4,
+		},
+		{
+			name:           "no amino acids reported",
+			amountsMg:      map[string]float64{"Iron": 1},
+			proteinG:       8,
+			wantUnreported: sortedPatternNames(),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := scoreAminoAcids(test.amountsMg, test.proteinG)
+			if (got.Score == nil) != (test.wantScore == nil) || (got.Score != nil && !closeTo(*got.Score, *test.wantScore)) {
+				t.Errorf("score = %v, want %v", deref(got.Score), deref(test.wantScore))
+			}
+			if got.LimitingAminoAcid != test.wantLimiting {
+				t.Errorf("limiting = %q, want %q", got.LimitingAminoAcid, test.wantLimiting)
+			}
+			if !slices.Equal(got.Unreported, test.wantUnreported) {
+				t.Errorf("unreported = %v, want %v", got.Unreported, test.wantUnreported)
+			}
+		})
+	}
+
+	// Ratios stay untruncated
+	if got := scoreAminoAcids(profile(10, 2, nil), 10).Ratios["Leucine"]; !closeTo(got, 2) {
+		t.Errorf("leucine ratio = %g, want 2", got)
+	}
+}
+
+func TestProteinQualityMeal(t *testing.T) {
+	amountsMg := map[string]map[string]float64{
+		"bread": profile(10, 1.2, map[string]float64{"Lysine": 25}),
+		"beans": profile(10, 1.5, map[string]float64{"Methionine": 9.6}),
+		"sauce": {"Sodium": 400},          // protein without amino acids: left out of the meal
+		"oil":   {"Linoleic Acid": 10000}, // no protein: not worth mentioning
+	}
+	macros := map[string]map[string]float64{"bread": protein(10), "beans": protein(10), "sauce": protein(2)}
+	quality := computeProteinQuality(amountsMg, macros)
+
+	// 20 g from bread and beans: lysine (250 + 675) / 20 / 45 = 1.028 now covered, methionine
+	// (192 + 96) / 20 / 16 = 0.9 limiting
+	meal := quality.Meal
+	if meal.Score == nil || !closeTo(*meal.Score, 0.9) || meal.LimitingAminoAcid != "Methionine" || meal.ProteinG != 20 {
+		t.Errorf("meal = %v limited by %s from %g g, want 0.9 limited by Methionine from 20 g", deref(meal.Score), meal.LimitingAminoAcid, meal.ProteinG)
+	}
+	if got := meal.Ratios["Lysine"]; !closeTo(got, 925.0/20/45) {
+		t.Errorf("meal lysine ratio = %g, want %g", got, 925.0/20/45)
+	}
+	if bread := quality.PerIngredient["bread"]; bread.Score == nil || !closeTo(*bread.Score, 25.0/45) {
+		t.Errorf("bread = %v, want %g on its own", deref(bread.Score), 25.0/45)
+	}
+	if !closeTo(quality.Coverage, 20.0/22) {
+		t.Errorf("coverage = %g, want 20/22", quality.Coverage)
+	}
+	if !slices.Equal(quality.Unscored, []string{"sauce"}) {
+		t.Errorf("unscored = %v, want [sauce]", quality.Unscored)
+	}
+}
+
+func TestProteinQualityWithoutProtein(t *testing.T) {
+	tests := []struct {
+		name      string
+		amountsMg map[string]map[string]float64
+		macros    map[string]map[string]float64
+		coverage  float64
+		unscored  []string
+	}{
+		{
+			// Nothing to cover: full coverage rather than 0/0
+			name:      "no protein at all",
+			amountsMg: map[string]map[string]float64{"oil": {"Linoleic Acid": 10000}},
+			coverage:  1,
+		},
+		{
+			name:      "no ingredient with a profile",
+			amountsMg: map[string]map[string]float64{"sauce": {"Sodium": 400}, "gravy": {"Sodium": 300}},
+			macros:    map[string]map[string]float64{"sauce": protein(2), "gravy": protein(3)},
+			coverage:  0,
+			unscored:  []string{"gravy", "sauce"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			quality := computeProteinQuality(test.amountsMg, test.macros)
+			if quality.Meal.Score != nil {
+				t.Errorf("meal score = %g, want none", *quality.Meal.Score)
+			}
+			if !closeTo(quality.Coverage, test.coverage) {
+				t.Errorf("coverage = %g, want %g", quality.Coverage, test.coverage)
+			}
+			if !slices.Equal(quality.Unscored, test.unscored) {
+				t.Errorf("unscored = %v, want %v", quality.Unscored, test.unscored)
+			}
+		})
+	}
+}
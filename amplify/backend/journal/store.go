@@ -0,0 +1,176 @@
+// The-Nutrimancers-Codex/amplify/backend/journal/store.go
+package journal
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/models"
+	bolt "go.etcd.io/bbolt"
+)
+
+var ErrNotFound = errors.New("journal: entry not found")
+
+var entriesBucket = []byte("entries")
+
+// Store keeps each user's journal entries in their own bbolt bucket, keyed by a sequence number
+type Store struct {
+	db *bolt.DB
+}
+
+// New uses db for the journal; the caller owns and closes it
+func New(db *bolt.DB) (*Store, error) {
+	err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(entriesBucket)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+/*=================================================================================================*/
+
+// Add saves a new entry for the user and returns it with its ID
+func (s *Store) Add(userID string, entry models.JournalEntry) (models.JournalEntry, error) {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.Bucket(entriesBucket).CreateBucketIfNotExists([]byte(userID))
+		if err != nil {
+			return err
+		}
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		entry.ID = strconv.FormatUint(seq, 10)
+		return put(bucket, seq, entry)
+	})
+	if err != nil {
+		return models.JournalEntry{}, err
+	}
+	return entry, nil
+}
+
+func (s *Store) Get(userID, id string) (models.JournalEntry, error) {
+	var entry models.JournalEntry
+	err := s.db.View(func(tx *bolt.Tx) error {
+		seq, err := parseID(id)
+		if err != nil {
+			return err
+		}
+		bucket := userBucket(tx, userID)
+		if bucket == nil {
+			return ErrNotFound
+		}
+		data := bucket.Get(key(seq))
+		if data == nil {
+			return ErrNotFound
+		}
+		return json.Unmarshal(data, &entry)
+	})
+	return entry, err
+}
+
+// Update replaces one of the user's entries; entry.ID picks which
+func (s *Store) Update(userID string, entry models.JournalEntry) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		seq, err := parseID(entry.ID)
+		if err != nil {
+			return err
+		}
+		bucket := userBucket(tx, userID)
+		if bucket == nil || bucket.Get(key(seq)) == nil {
+			return ErrNotFound
+		}
+		return put(bucket, seq, entry)
+	})
+}
+
+func (s *Store) Delete(userID, id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		seq, err := parseID(id)
+		if err != nil {
+			return err
+		}
+		bucket := userBucket(tx, userID)
+		if bucket == nil || bucket.Get(key(seq)) == nil {
+			return ErrNotFound
+		}
+		return bucket.Delete(key(seq))
+	})
+}
+
+// List returns the user's entries in time order; a non-empty date (models.DateLayout) keeps only that day
+func (s *Store) List(userID, date string) ([]models.JournalEntry, error) {
+	if date == "" {
+		return s.list(userID, func(string) bool { return true })
+	}
+	return s.ListBetween(userID, date, date)
+}
+
+// ListBetween returns the user's entries from one day to another, both inclusive (models.DateLayout)
+func (s *Store) ListBetween(userID, from, to string) ([]models.JournalEntry, error) {
+	// DateLayout sorts as text
+	return s.list(userID, func(date string) bool { return from <= date && date <= to })
+}
+
+func (s *Store) list(userID string, keep func(date string) bool) ([]models.JournalEntry, error) {
+	entries := []models.JournalEntry{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := userBucket(tx, userID)
+		if bucket == nil {
+			return nil // nothing logged yet
+		}
+		return bucket.ForEach(func(_, data []byte) error {
+			var entry models.JournalEntry
+			if err := json.Unmarshal(data, &entry); err != nil {
+				return err
+			}
+			if keep(entry.Timestamp.Format(models.DateLayout)) {
+				entries = append(entries, entry)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+	return entries, nil
+}
+
+/*=================================================================================================*/
+
+func userBucket(tx *bolt.Tx, userID string) *bolt.Bucket {
+	return tx.Bucket(entriesBucket).Bucket([]byte(userID))
+}
+
+func put(bucket *bolt.Bucket, seq uint64, entry models.JournalEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return bucket.Put(key(seq), data)
+}
+
+// Big-endian keys keep bbolt's byte order equal to insertion order
+func key(seq uint64) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, seq)
+	return k
+}
+
+// IDs are sequence numbers; anything else can't exist
+func parseID(id string) (uint64, error) {
+	seq, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return 0, ErrNotFound
+	}
+	return seq, nil
+}
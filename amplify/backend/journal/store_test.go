@@ -0,0 +1,198 @@
+// The-Nutrimancers-Codex/amplify/backend/journal/store_test.go
+package journal
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/models"
+	bolt "go.etcd.io/bbolt"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "journal.db"), 0o600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	store, err := New(db)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return store
+}
+
+func meal(timestamp string, slot models.MealSlot, ingredients ...string) models.JournalEntry {
+	parsed, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		panic(err)
+	}
+	return models.JournalEntry{
+		Timestamp: parsed,
+		Slot:      slot,
+		Analysis:  models.ProcessFoodResponse{SchemaVersion: models.SchemaVersion, Ingredients: ingredients},
+	}
+}
+
+func add(t *testing.T, store *Store, userID string, entry models.JournalEntry) models.JournalEntry {
+	t.Helper()
+	saved, err := store.Add(userID, entry)
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	return saved
+}
+
+func ids(entries []models.JournalEntry) []string {
+	ids := []string{}
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+	}
+	return ids
+}
+
+/*=================================================================================================*/
+
+func TestAddAndGet(t *testing.T) {
+	store := newTestStore(t)
+	first := add(t, store, "ada", meal("2026-10-17T08:00:00Z", models.SlotBreakfast, "egg", "toast"))
+	second := add(t, store, "ada", meal("2026-10-17T12:30:00Z", models.SlotLunch, "rice"))
+	if first.ID == "" || first.ID == second.ID {
+		t.Fatalf("IDs %q and %q, want distinct non-empty IDs", first.ID, second.ID)
+	}
+
+	got, err := store.Get("ada", first.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Slot != models.SlotBreakfast || !got.Timestamp.Equal(first.Timestamp) || len(got.Analysis.Ingredients) != 2 {
+		t.Errorf("Get = %+v, want the saved breakfast", got)
+	}
+	if _, err := store.Get("ada", "999"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get unknown ID = %v, want ErrNotFound", err)
+	}
+	if _, err := store.Get("ada", "not-an-id"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get malformed ID = %v, want ErrNotFound", err)
+	}
+}
+
+func TestListByDate(t *testing.T) {
+	store := newTestStore(t)
+	dinner := add(t, store, "ada", meal("2026-10-17T19:00:00Z", models.SlotDinner, "salmon"))
+	breakfast := add(t, store, "ada", meal("2026-10-17T08:00:00Z", models.SlotBreakfast, "egg"))
+	nextDay := add(t, store, "ada", meal("2026-10-18T08:00:00Z", models.SlotBreakfast, "oats"))
+	// Late evening in its own offset, the 18th in UTC - it counts towards the 17th
+	lateSnack := add(t, store, "ada", meal("2026-10-17T23:30:00-05:00", models.SlotSnack, "popcorn"))
+
+	tests := []struct {
+		name    string
+		list    func() ([]models.JournalEntry, error)
+		wantIDs []string
+	}{
+		{"all, in time order", func() ([]models.JournalEntry, error) { return store.List("ada", "") },
+			[]string{breakfast.ID, dinner.ID, lateSnack.ID, nextDay.ID}},
+		{"one day", func() ([]models.JournalEntry, error) { return store.List("ada", "2026-10-17") },
+			[]string{breakfast.ID, dinner.ID, lateSnack.ID}},
+		{"empty day", func() ([]models.JournalEntry, error) { return store.List("ada", "2026-10-16") },
+			[]string{}},
+		{"between, inclusive", func() ([]models.JournalEntry, error) { return store.ListBetween("ada", "2026-10-18", "2026-10-24") },
+			[]string{nextDay.ID}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entries, err := test.list()
+			if err != nil {
+				t.Fatalf("list: %v", err)
+			}
+			if got := ids(entries); !slices.Equal(got, test.wantIDs) {
+				t.Errorf("IDs = %v, want %v", got, test.wantIDs)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	store := newTestStore(t)
+	saved := add(t, store, "ada", meal("2026-10-17T08:00:00Z", models.SlotBreakfast, "egg"))
+
+	edited := meal("2026-10-16T13:00:00Z", models.SlotLunch, "egg", "spinach")
+	edited.ID = saved.ID
+	if err := store.Update("ada", edited); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	got, err := store.Get("ada", saved.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Slot != models.SlotLunch || len(got.Analysis.Ingredients) != 2 {
+		t.Errorf("after Update = %+v, want the edited lunch", got)
+	}
+	if entries, _ := store.List("ada", "2026-10-16"); !slices.Equal(ids(entries), []string{saved.ID}) {
+		t.Errorf("2026-10-16 = %v, want the entry moved to its new day", ids(entries))
+	}
+
+	edited.ID = "999"
+	if err := store.Update("ada", edited); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Update unknown ID = %v, want ErrNotFound", err)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	store := newTestStore(t)
+	kept := add(t, store, "ada", meal("2026-10-17T08:00:00Z", models.SlotBreakfast, "egg"))
+	deleted := add(t, store, "ada", meal("2026-10-17T12:00:00Z", models.SlotLunch, "rice"))
+
+	if err := store.Delete("ada", deleted.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Get("ada", deleted.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete = %v, want ErrNotFound", err)
+	}
+	if entries, _ := store.List("ada", ""); !slices.Equal(ids(entries), []string{kept.ID}) {
+		t.Errorf("entries after Delete = %v, want only %s", ids(entries), kept.ID)
+	}
+	if err := store.Delete("ada", deleted.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete = %v, want ErrNotFound", err)
+	}
+
+	// IDs aren't reused after a delete
+	if next := add(t, store, "ada", meal("2026-10-17T19:00:00Z", models.SlotDinner, "salmon")); next.ID == deleted.ID {
+		t.Errorf("new entry reused deleted ID %s", deleted.ID)
+	}
+}
+
+func TestUsersAreIsolated(t *testing.T) {
+	store := newTestStore(t)
+	adas := add(t, store, "ada", meal("2026-10-17T08:00:00Z", models.SlotBreakfast, "egg"))
+	add(t, store, "grace", meal("2026-10-17T09:00:00Z", models.SlotBreakfast, "oats"))
+
+	if entries, _ := store.List("grace", ""); len(entries) != 1 || entries[0].Analysis.Ingredients[0] != "oats" {
+		t.Errorf("grace's entries = %+v, want only her oats", entries)
+	}
+	if entries, _ := store.List("nobody", ""); len(entries) != 0 {
+		t.Errorf("entries of a user who logged nothing = %+v, want none", entries)
+	}
+
+	// grace's first entry has the same ID as ada's, but she can't reach ada's
+	if got, err := store.Get("grace", adas.ID); err != nil || got.Analysis.Ingredients[0] != "oats" {
+		t.Errorf("grace Get %s = %+v, %v; want her own entry", adas.ID, got, err)
+	}
+	if _, err := store.Get("linus", adas.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("linus Get = %v, want ErrNotFound", err)
+	}
+	edited := meal("2026-10-17T08:00:00Z", models.SlotSnack, "cake")
+	edited.ID = adas.ID
+	if err := store.Update("linus", edited); !errors.Is(err, ErrNotFound) {
+		t.Errorf("linus Update = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete("linus", adas.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("linus Delete = %v, want ErrNotFound", err)
+	}
+	if got, err := store.Get("ada", adas.ID); err != nil || got.Slot != models.SlotBreakfast {
+		t.Errorf("ada's entry = %+v, %v; want it untouched", got, err)
+	}
+}
@@ -0,0 +1,364 @@
+// The-Nutrimancers-Codex/amplify/backend/journalHandlers.go
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/analysis"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/journal"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/machinist"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/metrics"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/models"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/nutrients"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/utils"
+)
+
+func (s *Server) createJournalEntryHandler(w http.ResponseWriter, r *http.Request) {
+	entry, ok := decodeJournalEntry(w, r)
+	if !ok {
+		return
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+
+	entry, err := s.journal.Add(userID(r), entry)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error saving journal entry: "+err.Error())
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, entry)
+}
+
+func (s *Server) listJournalEntriesHandler(w http.ResponseWriter, r *http.Request) {
+	date := r.URL.Query().Get("date")
+	if date != "" {
+		if _, err := time.Parse(models.DateLayout, date); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid date: expected YYYY-MM-DD")
+			return
+		}
+	}
+
+	entries, err := s.journal.List(userID(r), date)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error reading journal: "+err.Error())
+		return
+	}
+	respondWithJSON(w, http.StatusOK, entries)
+}
+
+func (s *Server) getJournalEntryHandler(w http.ResponseWriter, r *http.Request) {
+	entry, err := s.journal.Get(userID(r), r.PathValue("id"))
+	if err != nil {
+		respondWithJournalError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, entry)
+}
+
+func (s *Server) updateJournalEntryHandler(w http.ResponseWriter, r *http.Request) {
+	saved, err := s.journal.Get(userID(r), r.PathValue("id"))
+	if err != nil {
+		respondWithJournalError(w, err)
+		return
+	}
+	entry, ok := decodeJournalEntry(w, r)
+	if !ok {
+		return
+	}
+	entry.ID = saved.ID
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = saved.Timestamp
+	}
+
+	if err := s.journal.Update(userID(r), entry); err != nil {
+		respondWithJournalError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, entry)
+}
+
+func (s *Server) deleteJournalEntryHandler(w http.ResponseWriter, r *http.Request) {
+	if err := s.journal.Delete(userID(r), r.PathValue("id")); err != nil {
+		respondWithJournalError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+/*=================================================================================*/
+
+func (s *Server) getJournalDayHandler(w http.ResponseWriter, r *http.Request) {
+	date := r.PathValue("date")
+	if _, err := time.Parse(models.DateLayout, date); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid date: expected YYYY-MM-DD")
+		return
+	}
+
+	entries, err := s.journal.List(userID(r), date)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error reading journal: "+err.Error())
+		return
+	}
+
+	totals, err := s.calculateDailyTotals(date, entries)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error totalling journal: "+err.Error())
+		return
+	}
+	respondWithJSON(w, http.StatusOK, totals)
+}
+
+// Day totals: every entry's ingredient amounts summed like one meal, judged against the full day
+// with the percentages recomputed for the profile
+func (s *Server) calculateDailyTotals(date string, entries []models.JournalEntry) (models.DailyTotals, error) {
+	// Latest entry's profile, default adult for an empty day
+	profile := nutrients.DefaultProfile
+	if len(entries) > 0 {
+		profile = entries[len(entries)-1].Analysis.Profile
+	}
+	profile, err := profile.Resolve()
+	if err != nil {
+		return models.DailyTotals{}, fmt.Errorf("invalid saved profile: %v", err)
+	}
+
+	entryIDs := []string{}
+	nutrientData := make(map[string]map[string]float64)
+	for _, entry := range entries {
+		entryIDs = append(entryIDs, entry.ID)
+		for ingredient, amounts := range analysisAmounts(entry.Analysis) {
+			nutrientData[entry.ID+"/"+ingredient] = amounts
+		}
+	}
+	micronutrientData, ingredientMacros := analysis.SplitMacros(nutrientData, profile)
+	nutrientDetails, err := analysis.CalculateNutrientDetails(micronutrientData, profile.Targets())
+	if err != nil {
+		return models.DailyTotals{}, err
+	}
+	totalNutrients := analysis.CalculateTotalNutrients(analysis.CalculateNutrientPercentages(nutrientDetails))
+	gaps := analysis.DetermineNutrientGaps(totalNutrients, nutrients.FullDay, s.analyzer.Thresholds)
+
+	// Macros per entry rather than per ingredient
+	macros := analysis.NewMacros(profile)
+	macros.Total = ingredientMacros.Total
+	for _, entry := range entries {
+		macros.PerIngredient[entry.ID] = make(map[string]float64)
+	}
+	for key, amounts := range ingredientMacros.PerIngredient {
+		entryID, _, _ := strings.Cut(key, "/")
+		for name, amount := range amounts {
+			macros.PerIngredient[entryID][name] += amount
+		}
+	}
+
+	return models.DailyTotals{
+		Date:             date,
+		EntryIDs:         entryIDs,
+		Nutrients:        totalNutrients,
+		MissingNutrients: analysis.DetermineLowAndMissingNutrients(gaps),
+		Gaps:             gaps,
+		ExcessNutrients:  analysis.DetermineExcessNutrients(totalNutrients, profile),
+		Macros:           macros,
+		Profile:          profile,
+	}, nil
+}
+
+/*=================================================================================*/
+
+var trendWindows = []int{7, 30}
+
+// Below target on at least this many logged days before a nutrient can be chronic
+const minChronicDays = 3
+
+// Rolling 7/30-day averages of the daily totals ending at ?date= (default today)
+func (s *Server) getJournalTrendsHandler(w http.ResponseWriter, r *http.Request) {
+	end := time.Now()
+	if date := r.URL.Query().Get("date"); date != "" {
+		parsed, err := time.Parse(models.DateLayout, date)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid date: expected YYYY-MM-DD")
+			return
+		}
+		end = parsed
+	}
+
+	longest := trendWindows[len(trendWindows)-1]
+	entries, err := s.journal.ListBetween(userID(r), windowStart(end, longest), end.Format(models.DateLayout))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error reading journal: "+err.Error())
+		return
+	}
+
+	// Each logged day totalled once, reused by every window
+	entriesPerDay := make(map[string][]models.JournalEntry)
+	for _, entry := range entries {
+		date := entry.Timestamp.Format(models.DateLayout)
+		entriesPerDay[date] = append(entriesPerDay[date], entry)
+	}
+	dailyTotals := make([]models.DailyTotals, 0, len(entriesPerDay))
+	for date, dayEntries := range entriesPerDay {
+		totals, err := s.calculateDailyTotals(date, dayEntries)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Error totalling journal: "+err.Error())
+			return
+		}
+		dailyTotals = append(dailyTotals, totals)
+	}
+	sort.Slice(dailyTotals, func(i, j int) bool { return dailyTotals[i].Date < dailyTotals[j].Date })
+
+	response := models.NutrientTrends{End: end.Format(models.DateLayout), Windows: []models.TrendWindow{}}
+	for _, days := range trendWindows {
+		response.Windows = append(response.Windows, s.calculateTrendWindow(dailyTotals, end, days))
+	}
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// Averages over the logged days of the window, skipping unlogged ones (see models.NutrientTrend);
+// chronic deficiencies go to the recommender weighted by the share of logged days they fell short
+func (s *Server) calculateTrendWindow(dailyTotals []models.DailyTotals, end time.Time, days int) models.TrendWindow {
+	window := models.TrendWindow{
+		Days:                days,
+		Start:               windowStart(end, days),
+		End:                 end.Format(models.DateLayout),
+		LoggedDays:          []string{},
+		Nutrients:           make(map[string]models.NutrientTrend),
+		ChronicDeficiencies: []string{},
+	}
+
+	for _, totals := range dailyTotals {
+		if totals.Date < window.Start || totals.Date > window.End {
+			continue
+		}
+		window.LoggedDays = append(window.LoggedDays, totals.Date)
+		for _, gap := range totals.Gaps {
+			trend := window.Nutrients[gap.Nutrient]
+			trend.AveragePercent += gap.Percentage // summed here, divided below
+			trend.DaysLogged++
+			if gap.Status != models.GapMet {
+				trend.DaysBelowTarget++
+			}
+			window.Nutrients[gap.Nutrient] = trend
+		}
+	}
+
+	weights := make(map[string]float64)
+	for _, name := range nutrients.Names() {
+		trend, exists := window.Nutrients[name]
+		if !exists {
+			continue
+		}
+		trend.AveragePercent /= float64(trend.DaysLogged)
+		window.Nutrients[name] = trend
+
+		if trend.DaysBelowTarget >= minChronicDays && trend.DaysBelowTarget*2 > trend.DaysLogged && trend.AveragePercent < 100 {
+			window.ChronicDeficiencies = append(window.ChronicDeficiencies, name)
+			weights[name] = float64(trend.DaysBelowTarget) / float64(trend.DaysLogged)
+		}
+	}
+	if len(weights) > 0 {
+		start := time.Now()
+		window.Suggestions = machinist.RecommendFoodsWeighted(s.analyzer.FoodItems, s.analyzer.NutrientNames, weights, s.analyzer.TopN)
+		metrics.ObserveRecommender("trends", start)
+	}
+	return window
+}
+
+// First day of a window of days ending at end
+func windowStart(end time.Time, days int) string {
+	return end.AddDate(0, 0, -(days - 1)).Format(models.DateLayout)
+}
+
+/*=================================================================================*/
+
+// Absolute amounts per ingredient of a saved analysis, micronutrients and macros together, for
+// percentages recomputed against another profile
+func analysisAmounts(analysis models.ProcessFoodResponse) map[string]map[string]float64 {
+	amounts := make(map[string]map[string]float64, len(analysis.Ingredients))
+	for _, ingredient := range analysis.Ingredients {
+		ingredientAmounts := make(map[string]float64)
+		for name, detail := range analysis.NutrientDetails[ingredient] {
+			ingredientAmounts[name] = detail.Amount
+		}
+		for name, amount := range analysis.Macros.PerIngredient[ingredient] {
+			ingredientAmounts[name] = amount
+		}
+		amounts[ingredient] = ingredientAmounts
+	}
+	return amounts
+}
+
+// Request body -> entry with a validated slot and analysis; responds with an error itself on failure
+func decodeJournalEntry(w http.ResponseWriter, r *http.Request) (models.JournalEntry, bool) {
+	var req models.JournalEntryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Error decoding request: "+err.Error())
+		return models.JournalEntry{}, false
+	}
+	slot, err := req.Slot.Resolve()
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid slot: "+err.Error())
+		return models.JournalEntry{}, false
+	}
+	if err := validateAnalysis(req.Analysis); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid analysis: "+err.Error())
+		return models.JournalEntry{}, false
+	}
+
+	entry := models.JournalEntry{Slot: slot, Analysis: req.Analysis}
+	if req.Timestamp != nil {
+		entry.Timestamp = *req.Timestamp
+	}
+	return entry, true
+}
+
+// The day totals rely on an analysis's profile and absolute amounts; anything else is stored as sent
+func validateAnalysis(analysis models.ProcessFoodResponse) error {
+	if analysis.SchemaVersion != models.SchemaVersion {
+		return fmt.Errorf("schemaVersion must be %d, got %d", models.SchemaVersion, analysis.SchemaVersion)
+	}
+	if len(analysis.Ingredients) == 0 {
+		return errors.New("at least one ingredient is required")
+	}
+	if _, err := analysis.Profile.Resolve(); err != nil {
+		return err
+	}
+	for ingredient, details := range analysis.NutrientDetails {
+		for name, detail := range details {
+			nutrient, ok := nutrients.Lookup(name)
+			if !ok || nutrient.IsMacro() {
+				return fmt.Errorf("%s: %q is not a tracked micronutrient", ingredient, name)
+			}
+			if detail.Unit != nutrient.Unit {
+				return fmt.Errorf("%s: %s must be in %s, got %q", ingredient, name, nutrient.Unit, detail.Unit)
+			}
+		}
+	}
+	for ingredient, amounts := range analysis.Macros.PerIngredient {
+		for name := range amounts {
+			if nutrient, ok := nutrients.Lookup(name); !ok || !nutrient.IsMacro() {
+				return fmt.Errorf("%s: %q is not a macronutrient", ingredient, name)
+			}
+		}
+	}
+	return nil
+}
+
+func respondWithJournalError(w http.ResponseWriter, err error) {
+	if errors.Is(err, journal.ErrNotFound) {
+		utils.RespondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	utils.RespondWithError(w, http.StatusInternalServerError, "Error reading journal: "+err.Error())
+}
+
+func respondWithJSON(w http.ResponseWriter, code int, payload any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(payload)
+}
@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/models"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/nutrients"
 )
 
 func LoadFoodData(filePath string) ([]models.FoodItem, []string, error) {
@@ -31,9 +33,22 @@ func LoadFoodData(filePath string) ([]models.FoodItem, []string, error) {
 
 	// Header processing
 	header := records[0]
-	nutrientNames := header[2:] // Nutrient names start from column 3
+	columns := header[2:] // Nutrient columns start from column 3
+	if err := nutrients.ValidateDatasetHeader(columns); err != nil {
+		return nil, nil, err
+	}
+	columnNutrients := make([]nutrients.Nutrient, len(columns))
+	var nutrientNames []string // recommender vector - micronutrients only
+	for i, column := range columns {
+		nutrient, _ := nutrients.ByDatasetColumn(column)
+		columnNutrients[i] = nutrient
+		if !nutrient.IsMacro() {
+			nutrientNames = append(nutrientNames, nutrient.Name)
+		}
+	}
 
 	var foodItems []models.FoodItem
+	reported := make([]bool, len(columns))
 
 	for _, record := range records[1:] {
 		if len(record) < 2 {
@@ -46,19 +61,36 @@ func LoadFoodData(filePath string) ([]models.FoodItem, []string, error) {
 			Nutrients:   make(map[string]float64),
 		}
 
-		for i, nutrientName := range nutrientNames {
+		for i, nutrient := range columnNutrients {
 			if i+2 >= len(record) {
 				break
 			}
 			valueStr := record[i+2]
 			value, err := strconv.ParseFloat(valueStr, 64)
 			if err != nil {
+				if nutrient.Sparse {
+					continue // not tracked by source, rather than zero
+				}
 				value = 0.0 // missing/invalid
+			} else {
+				reported[i] = true
 			}
-			foodItem.Nutrients[nutrientName] = value
+			foodItem.Nutrients[nutrient.Name] = value
 		}
 		foodItems = append(foodItems, foodItem)
 	}
 
+	// An empty column would score every food as zero for that nutrient. Sparse nutrients may
+	// go unreported - they come back untracked rather than missing.
+	var empty []string
+	for i, nutrient := range columnNutrients {
+		if !reported[i] && !nutrient.Sparse {
+			empty = append(empty, nutrient.Name)
+		}
+	}
+	if len(empty) > 0 {
+		return nil, nil, fmt.Errorf("no food in the dataset reports %s; regenerate it with data/preprocessor.py", strings.Join(empty, ", "))
+	}
+
 	return foodItems, nutrientNames, nil
 }
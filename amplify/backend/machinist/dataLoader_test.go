@@ -0,0 +1,75 @@
+// The-Nutrimancers-Codex/amplify/backend/machinist/dataLoader_test.go
+package machinist
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/nutrients"
+)
+
+// The committed dataset must load as the server loads it at startup, so a registry change
+// without a regenerated dataset fails here rather than in production
+func TestCommittedDataset(t *testing.T) {
+	foodItems, nutrientNames, err := LoadFoodData("dataset.csv")
+	if err != nil {
+		t.Fatalf("LoadFoodData(dataset.csv): %v", err)
+	}
+	if len(foodItems) == 0 {
+		t.Fatal("dataset.csv has no foods")
+	}
+	for _, name := range nutrientNames {
+		if nutrient, ok := nutrients.Lookup(name); !ok || nutrient.IsMacro() {
+			t.Errorf("recommender nutrient %q isn't a registered micronutrient", name)
+		}
+	}
+	for _, nutrient := range nutrients.All() {
+		if !nutrient.IsMacro() && !nutrient.Sparse && !slices.Contains(nutrientNames, nutrient.Name) {
+			t.Errorf("dataset.csv has no %s column", nutrient.Name)
+		}
+	}
+}
+
+// writeDataset writes a dataset of one food reporting 1 of every column and returns its path
+func writeDataset(t *testing.T, columns []string) string {
+	t.Helper()
+	header := append([]string{"fdc_id", "description"}, columns...)
+	row := []string{"1", "Spinach"}
+	for range columns {
+		row = append(row, "1")
+	}
+	path := filepath.Join(t.TempDir(), "dataset.csv")
+	if err := os.WriteFile(path, []byte(strings.Join(header, ",")+"\n"+strings.Join(row, ",")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadFoodDataHeader(t *testing.T) {
+	var required []string
+	for _, nutrient := range nutrients.All() {
+		if !nutrient.IsMacro() && !nutrient.Sparse {
+			required = append(required, nutrient.DatasetColumn)
+		}
+	}
+	tests := []struct {
+		name    string
+		columns []string
+		wantErr bool
+	}{
+		{"required columns only", required, false},
+		{"with macro and sparse columns", append(slices.Clone(required), "Protein", "Iodine"), false},
+		{"missing registry column", required[1:], true},
+		{"unregistered column", append(slices.Clone(required), "Unobtainium"), true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, _, err := LoadFoodData(writeDataset(t, test.columns)); (err != nil) != test.wantErr {
+				t.Errorf("LoadFoodData error = %v, want error %t", err, test.wantErr)
+			}
+		})
+	}
+}
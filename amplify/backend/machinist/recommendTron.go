@@ -5,6 +5,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/derived"
 	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/models"
 )
 
@@ -16,7 +17,16 @@ type Recommendation struct {
 
 // RecommendFoods recommends topN food items based on nutrient deficiencies
 func RecommendFoods(foodItems []models.FoodItem, nutrientNames []string, deficiencies []string, topN int) []string {
-	deficiencyVector := createDeficiencyVector(nutrientNames, deficiencies)
+	weights := make(map[string]float64)
+	for _, nutrient := range deficiencies {
+		weights[nutrient] = 1.0
+	}
+	return RecommendFoodsWeighted(foodItems, nutrientNames, weights, topN)
+}
+
+// RecommendFoodsWeighted is RecommendFoods with per-nutrient weights (negative = avoid)
+func RecommendFoodsWeighted(foodItems []models.FoodItem, nutrientNames []string, weights map[string]float64, topN int) []string {
+	targetVector := createWeightVector(nutrientNames, weights)
 
 	var recommendations []Recommendation
 
@@ -25,15 +35,17 @@ func RecommendFoods(foodItems []models.FoodItem, nutrientNames []string, deficie
 		for i, nutrientName := range nutrientNames {
 			foodVector[i] = food.Nutrients[nutrientName]
 		}
-		similarity := CosineSimilarity(foodVector, deficiencyVector)
+		similarity := CosineSimilarity(foodVector, targetVector)
 		if similarity > 0 {
 			recommendation := Recommendation{
 				Description:     food.Description,
 				SimilarityScore: similarity,
 				Nutrients:       make(map[string]float64),
 			}
-			for _, nutrient := range deficiencies {
-				recommendation.Nutrients[nutrient] = food.Nutrients[nutrient]
+			for nutrient, weight := range weights {
+				if weight > 0 {
+					recommendation.Nutrients[nutrient] = food.Nutrients[nutrient]
+				}
 			}
 			recommendations = append(recommendations, recommendation)
 		}
@@ -51,6 +63,51 @@ func RecommendFoods(foodItems []models.FoodItem, nutrientNames []string, deficie
 	return suggestedFoods
 }
 
+const minEssentialAminoAcidsG = 2.0 // per 100 g; below this a food isn't a meaningful protein source
+
+// RecommendComplementaryProteins recommends topN protein sources rich in the meal's limiting
+// amino acid, e.g. legumes for a lysine-limited grain meal. Foods are ranked by that amino
+// acid's share of their essential amino acids, relative to its share of the reference pattern.
+func RecommendComplementaryProteins(foodItems []models.FoodItem, limitingAminoAcid string, topN int) []string {
+	reference, ok := derived.ReferencePattern[limitingAminoAcid]
+	if !ok {
+		return nil
+	}
+	referenceTotal := 0.0
+	for _, amount := range derived.ReferencePattern {
+		referenceTotal += amount
+	}
+	referenceShare := reference / referenceTotal
+
+	var recommendations []Recommendation
+	for _, food := range foodItems {
+		essentialTotal := 0.0
+		for name := range derived.ReferencePattern {
+			essentialTotal += food.Nutrients[name]
+		}
+		if essentialTotal < minEssentialAminoAcidsG {
+			continue
+		}
+		share := food.Nutrients[limitingAminoAcid] / essentialTotal
+		if share <= referenceShare {
+			continue
+		}
+		recommendations = append(recommendations, Recommendation{
+			Description:     food.Description,
+			SimilarityScore: share / referenceShare,
+			Nutrients:       map[string]float64{limitingAminoAcid: food.Nutrients[limitingAminoAcid]},
+		})
+	}
+	sort.Slice(recommendations, func(i, j int) bool {
+		return recommendations[i].SimilarityScore > recommendations[j].SimilarityScore
+	})
+	var suggestedFoods []string
+	for _, rec := range deduplicateRecommendations(recommendations, topN) {
+		suggestedFoods = append(suggestedFoods, rec.Description)
+	}
+	return suggestedFoods
+}
+
 func deduplicateRecommendations(recommendations []Recommendation, topN int) []Recommendation {
 	uniqueRecommendations := []Recommendation{}
 	seenDescriptions := make(map[string]bool)
@@ -83,20 +140,11 @@ func extractPrimaryIdentifier(description string) string {
 	return primary
 }
 
-// Binary vector indicating deficiencies
-func createDeficiencyVector(nutrientNames []string, deficiencies []string) []float64 {
-	deficiencyVector := make([]float64, len(nutrientNames))
-	deficiencySet := make(map[string]bool)
-	for _, nutrient := range deficiencies {
-		deficiencySet[nutrient] = true
-	}
-
+// Target vector: 1.0 for deficiencies, derived-metric weights otherwise, 0 elsewhere
+func createWeightVector(nutrientNames []string, weights map[string]float64) []float64 {
+	weightVector := make([]float64, len(nutrientNames))
 	for i, nutrientName := range nutrientNames {
-		if deficiencySet[nutrientName] {
-			deficiencyVector[i] = 1.0
-		} else {
-			deficiencyVector[i] = 0.0
-		}
+		weightVector[i] = weights[nutrientName]
 	}
-	return deficiencyVector
+	return weightVector
 }
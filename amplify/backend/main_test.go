@@ -0,0 +1,404 @@
+// The-Nutrimancers-Codex/amplify/backend/main_test.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/analysis"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/config"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/models"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/nutrients"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/services"
+)
+
+// stubParser answers every description with the same ingredients
+type stubParser []models.Ingredient
+
+func (p stubParser) Ready() error {
+	return nil
+}
+
+func (p stubParser) ExtractIngredients(ctx context.Context, foodDescription string) ([]models.Ingredient, error) {
+	return p, nil
+}
+
+// fakeProvider answers from a table; foods missing from it aren't found
+type fakeProvider struct {
+	foods map[string]services.FoodNutrients
+	err   error // returned for every lookup when set
+}
+
+func (p fakeProvider) Name() string {
+	return "fake"
+}
+
+func (p fakeProvider) Ready() error {
+	return nil
+}
+
+func (p fakeProvider) Lookup(ctx context.Context, ingredient models.Ingredient) (services.FoodNutrients, error) {
+	if p.err != nil {
+		return services.FoodNutrients{}, p.err
+	}
+	result, ok := p.foods[ingredient.Food]
+	if !ok {
+		return services.FoodNutrients{}, services.ErrFoodNotFound
+	}
+	return result, nil
+}
+
+func newTestServer(parser services.IngredientParser, provider services.NutrientProvider) *Server {
+	return &Server{
+		config: config.Default(),
+		analyzer: &analysis.Analyzer{
+			Parser:   parser,
+			Provider: provider,
+			FoodItems: []models.FoodItem{
+				{FdcID: "1", Description: "Spinach, raw", Nutrients: map[string]float64{"Iron": 2.7, "Vitamin C": 28}},
+				{FdcID: "2", Description: "Orange, raw", Nutrients: map[string]float64{"Vitamin C": 53}},
+			},
+			NutrientNames: []string{"Iron", "Vitamin C"},
+			Thresholds:    nutrients.DefaultThresholds,
+			TopN:          2,
+			Concurrency:   2,
+		},
+	}
+}
+
+func postJSON(handler http.HandlerFunc, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest(http.MethodPost, "/v1/meal-analyses", strings.NewReader(body)))
+	return recorder
+}
+
+/*=================================================================================================*/
+
+func TestProcessFoodHandler(t *testing.T) {
+	server := newTestServer(
+		stubParser{{Food: "egg", Quantity: 2}, {Food: "rice", Quantity: 200, Unit: "g"}},
+		fakeProvider{foods: map[string]services.FoodNutrients{
+			"egg": {
+				Amounts: map[string]float64{"Iron": 1.8, "Protein": 12.6},
+				Portion: models.Portion{Quantity: 2, Unit: "large", Grams: 100},
+				Source:  models.FoodSource{Provider: "fake", ID: "171287"},
+			},
+		}},
+	)
+
+	recorder := postJSON(server.processFoodHandler, `{"foodDescription": "two eggs and 200 g of rice"}`)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200: %s", recorder.Code, recorder.Body)
+	}
+	var response models.ProcessFoodResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+
+	if strings.Join(response.Ingredients, ",") != "egg,rice" {
+		t.Errorf("ingredients = %v, want [egg rice]", response.Ingredients)
+	}
+	// 1.8 mg of the adult default's 8 mg iron
+	if got := response.Nutrients["egg"]["Iron"]; got < 22.4 || got > 22.6 {
+		t.Errorf("egg iron = %g%%, want 22.5%%", got)
+	}
+	if got := response.Macros.Total["Protein"]; got != 12.6 {
+		t.Errorf("protein = %g g, want 12.6", got)
+	}
+	if got := response.Portions["rice"]; got.Quantity != 200 || got.Unit != "g" || got.Assumed {
+		t.Errorf("rice portion = %+v, want the stated 200 g", got)
+	}
+	if _, found := response.Sources["rice"]; found {
+		t.Errorf("rice has a source, but the provider didn't find it")
+	}
+	if response.Sources["egg"].ID != "171287" {
+		t.Errorf("egg source = %+v, want fdc_id 171287", response.Sources["egg"])
+	}
+	if len(response.Suggestions) == 0 {
+		t.Errorf("no suggestions for a meal missing vitamin C")
+	}
+}
+
+func TestProcessFoodHandlerErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider services.NutrientProvider
+		body     string
+		status   int
+		message  string
+	}{
+		{"malformed body", fakeProvider{}, `{"foodDescription": `, http.StatusBadRequest, "Error decoding request"},
+		{"empty description", fakeProvider{}, `{"foodDescription": "  "}`, http.StatusBadRequest, "foodDescription is required"},
+		{"unknown context", fakeProvider{}, `{"foodDescription": "an egg", "context": "brunch"}`, http.StatusBadRequest, "Invalid context"},
+		{"provider failure", fakeProvider{err: errors.New("upstream down")}, `{"foodDescription": "an egg"}`, http.StatusInternalServerError, "upstream down"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newTestServer(stubParser{{Food: "egg", Quantity: 1}}, test.provider)
+			recorder := postJSON(server.processFoodHandler, test.body)
+			if recorder.Code != test.status {
+				t.Errorf("status %d, want %d: %s", recorder.Code, test.status, recorder.Body)
+			}
+			var body struct {
+				Error string `json:"error"`
+			}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil || !strings.Contains(body.Error, test.message) {
+				t.Errorf("error body %s, want one containing %q", recorder.Body, test.message)
+			}
+		})
+	}
+}
+
+func TestJournalEntryKeepsTheAnalysis(t *testing.T) {
+	server := newTestServer(
+		stubParser{{Food: "egg", Quantity: 2}},
+		fakeProvider{foods: map[string]services.FoodNutrients{
+			"egg": {Amounts: map[string]float64{"Iron": 1.8, "Protein": 12.6}, Portion: models.Portion{Quantity: 2, Unit: "large", Grams: 100}},
+		}},
+	)
+	analysis, failure := server.analyzer.AnalyzeMeal(context.Background(), models.ProcessFoodRequest{FoodDescription: "two eggs"}, nil)
+	if failure != nil {
+		t.Fatalf("AnalyzeMeal: %v", failure)
+	}
+	// Saving must not look anything up again
+	server.analyzer.Provider = fakeProvider{err: errors.New("provider called")}
+
+	body, _ := json.Marshal(models.JournalEntryRequest{Slot: models.SlotBreakfast, Analysis: analysis})
+	request := httptest.NewRequest(http.MethodPost, "/v1/journal/entries", strings.NewReader(string(body)))
+	entry, ok := decodeJournalEntry(httptest.NewRecorder(), request)
+	if !ok {
+		t.Fatal("decodeJournalEntry rejected an analysis straight from AnalyzeMeal")
+	}
+	saved, _ := json.Marshal(entry.Analysis)
+	sent, _ := json.Marshal(analysis)
+	if string(saved) != string(sent) {
+		t.Errorf("saved analysis differs from the one sent:\n%s\n%s", saved, sent)
+	}
+
+	// Two such breakfasts: 3.6 mg of the adult default's 8 mg iron, 25.2 g protein
+	entry.ID = "1"
+	second := entry
+	second.ID = "2"
+	totals, err := server.calculateDailyTotals("2026-10-17", []models.JournalEntry{entry, second})
+	if err != nil {
+		t.Fatalf("calculateDailyTotals: %v", err)
+	}
+	if got := totals.Nutrients["Iron"]; got < 44.9 || got > 45.1 {
+		t.Errorf("day iron = %g%%, want 45%%", got)
+	}
+	if got := totals.Macros.Total["Protein"]; got < 25.19 || got > 25.21 {
+		t.Errorf("day protein = %g g, want 25.2", got)
+	}
+	if got := totals.Macros.PerIngredient["2"]["Protein"]; got != 12.6 {
+		t.Errorf("entry 2 protein = %g g, want 12.6", got)
+	}
+}
+
+func TestJournalEntryValidation(t *testing.T) {
+	valid := func() models.ProcessFoodResponse {
+		return models.ProcessFoodResponse{
+			SchemaVersion: models.SchemaVersion,
+			Ingredients:   []string{"egg"},
+			NutrientDetails: map[string]map[string]models.NutrientAmount{
+				"egg": {"Iron": {Amount: 1.8, Unit: "mg"}},
+			},
+		}
+	}
+	tests := []struct {
+		name    string
+		slot    models.MealSlot
+		edit    func(*models.ProcessFoodResponse)
+		message string
+	}{
+		{"unknown slot", "elevenses", func(*models.ProcessFoodResponse) {}, "Invalid slot"},
+		{"old schema", models.SlotLunch, func(a *models.ProcessFoodResponse) { a.SchemaVersion = 1 }, "schemaVersion"},
+		{"no ingredients", models.SlotLunch, func(a *models.ProcessFoodResponse) { a.Ingredients = nil }, "ingredient"},
+		{"unregistered nutrient", models.SlotLunch, func(a *models.ProcessFoodResponse) {
+			a.NutrientDetails["egg"]["Unobtainium"] = models.NutrientAmount{Amount: 1, Unit: "mg"}
+		}, "Unobtainium"},
+		{"wrong unit", models.SlotLunch, func(a *models.ProcessFoodResponse) {
+			a.NutrientDetails["egg"]["Iron"] = models.NutrientAmount{Amount: 1800, Unit: "µg"}
+		}, "Iron must be in mg"},
+		{"micronutrient as a macro", models.SlotLunch, func(a *models.ProcessFoodResponse) {
+			a.Macros.PerIngredient = map[string]map[string]float64{"egg": {"Iron": 1.8}}
+		}, "not a macronutrient"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			analysis := valid()
+			test.edit(&analysis)
+			body, _ := json.Marshal(models.JournalEntryRequest{Slot: test.slot, Analysis: analysis})
+			recorder := httptest.NewRecorder()
+			if _, ok := decodeJournalEntry(recorder, httptest.NewRequest(http.MethodPost, "/v1/journal/entries", strings.NewReader(string(body)))); ok {
+				t.Fatal("decodeJournalEntry accepted it")
+			}
+			if recorder.Code != http.StatusBadRequest || !strings.Contains(recorder.Body.String(), test.message) {
+				t.Errorf("status %d %s, want 400 mentioning %q", recorder.Code, recorder.Body, test.message)
+			}
+		})
+	}
+}
+
+// countingParser is stubParser that counts the descriptions it was asked to parse
+type countingParser struct {
+	stubParser
+	calls *atomic.Int32
+}
+
+func (p countingParser) ExtractIngredients(ctx context.Context, foodDescription string) ([]models.Ingredient, error) {
+	p.calls.Add(1)
+	return p.stubParser, nil
+}
+
+func TestBatchValidatesItemsUpFront(t *testing.T) {
+	parser := countingParser{stubParser{{Food: "egg", Quantity: 1}}, &atomic.Int32{}}
+	server := newTestServer(parser, fakeProvider{})
+
+	recorder := postJSON(server.processFoodBatchHandler, `{"items": [
+		{"foodDescription": "an egg"},
+		{"foodDescription": " "},
+		{"foodDescription": "an egg", "context": "brunch"},
+		{"foodDescription": "another egg"}
+	]}`)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200: %s", recorder.Code, recorder.Body)
+	}
+	var response models.BatchProcessFoodResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+
+	wantStatus := []int{http.StatusOK, http.StatusBadRequest, http.StatusBadRequest, http.StatusOK}
+	for i, result := range response.Results {
+		if result.Index != i || result.Status != wantStatus[i] {
+			t.Errorf("result %d = index %d, status %d (%s); want status %d", i, result.Index, result.Status, result.Error, wantStatus[i])
+		}
+	}
+	if response.Succeeded != 2 || response.Failed != 2 {
+		t.Errorf("succeeded %d, failed %d; want 2 and 2", response.Succeeded, response.Failed)
+	}
+	if calls := parser.calls.Load(); calls != 2 {
+		t.Errorf("parser called %d times, want only for the 2 valid items", calls)
+	}
+}
+
+func TestStreamRejectsInvalidRequestsBeforeStreaming(t *testing.T) {
+	parser := countingParser{stubParser{{Food: "egg", Quantity: 1}}, &atomic.Int32{}}
+	server := newTestServer(parser, fakeProvider{})
+
+	recorder := postJSON(server.processFoodStreamHandler, `{"foodDescription": "an egg", "profile": {"age": 300}}`)
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("status %d, want 400: %s", recorder.Code, recorder.Body)
+	}
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("Content-Type %q, want a plain JSON error rather than an event stream", contentType)
+	}
+	if calls := parser.calls.Load(); calls != 0 {
+		t.Errorf("parser called %d times for an invalid request", calls)
+	}
+}
+
+// A logged day's totals with the given iron and vitamin C percentages; 0 means not tracked that day
+func trendDay(date string, iron, vitaminC float64) models.DailyTotals {
+	totals := models.DailyTotals{Date: date}
+	for _, gap := range []models.NutrientGap{{Nutrient: "Iron", Percentage: iron}, {Nutrient: "Vitamin C", Percentage: vitaminC}} {
+		if gap.Percentage == 0 {
+			continue
+		}
+		gap.Status = models.GapMet
+		if gap.Percentage < 100 {
+			gap.Status = models.GapLow
+		}
+		totals.Gaps = append(totals.Gaps, gap)
+	}
+	return totals
+}
+
+func TestTrendWindowWithGaps(t *testing.T) {
+	server := newTestServer(stubParser{}, fakeProvider{})
+	end, _ := time.Parse(models.DateLayout, "2026-10-17")
+
+	tests := []struct {
+		name        string
+		days        []models.DailyTotals
+		loggedDays  []string
+		iron        models.NutrientTrend
+		vitaminC    models.NutrientTrend
+		wantChronic []string
+	}{
+		{
+			// 40, 50, 30 and 120% over the 4 logged days of the week: unlogged days are missing data, not 0%
+			name: "gaps in the week",
+			days: []models.DailyTotals{
+				trendDay("2026-10-10", 10, 0), // the day before the window
+				trendDay("2026-10-11", 40, 0),
+				trendDay("2026-10-13", 50, 0),
+				trendDay("2026-10-16", 30, 0),
+				trendDay("2026-10-17", 120, 200),
+			},
+			loggedDays:  []string{"2026-10-11", "2026-10-13", "2026-10-16", "2026-10-17"},
+			iron:        models.NutrientTrend{AveragePercent: 60, DaysBelowTarget: 3, DaysLogged: 4},
+			vitaminC:    models.NutrientTrend{AveragePercent: 200, DaysBelowTarget: 0, DaysLogged: 1},
+			wantChronic: []string{"Iron"},
+		},
+		{
+			// Short on every logged day, but two days aren't enough to call it chronic
+			name:        "two logged days",
+			days:        []models.DailyTotals{trendDay("2026-10-12", 20, 50), trendDay("2026-10-15", 40, 150)},
+			loggedDays:  []string{"2026-10-12", "2026-10-15"},
+			iron:        models.NutrientTrend{AveragePercent: 30, DaysBelowTarget: 2, DaysLogged: 2},
+			vitaminC:    models.NutrientTrend{AveragePercent: 100, DaysBelowTarget: 1, DaysLogged: 2},
+			wantChronic: []string{},
+		},
+		{
+			// Below target on 3 of 7 logged days: not most of them
+			name: "short on a minority of days",
+			days: []models.DailyTotals{
+				trendDay("2026-10-11", 10, 0), trendDay("2026-10-12", 10, 0), trendDay("2026-10-13", 10, 0),
+				trendDay("2026-10-14", 150, 0), trendDay("2026-10-15", 150, 0), trendDay("2026-10-16", 150, 0),
+				trendDay("2026-10-17", 150, 0),
+			},
+			loggedDays:  []string{"2026-10-11", "2026-10-12", "2026-10-13", "2026-10-14", "2026-10-15", "2026-10-16", "2026-10-17"},
+			iron:        models.NutrientTrend{AveragePercent: 90, DaysBelowTarget: 3, DaysLogged: 7},
+			wantChronic: []string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			window := server.calculateTrendWindow(test.days, end, 7)
+			if window.Start != "2026-10-11" || window.End != "2026-10-17" {
+				t.Errorf("window %s to %s, want 2026-10-11 to 2026-10-17", window.Start, window.End)
+			}
+			if !slices.Equal(window.LoggedDays, test.loggedDays) {
+				t.Errorf("logged days = %v, want %v", window.LoggedDays, test.loggedDays)
+			}
+			for name, want := range map[string]models.NutrientTrend{"Iron": test.iron, "Vitamin C": test.vitaminC} {
+				got, tracked := window.Nutrients[name]
+				if want.DaysLogged == 0 {
+					if tracked {
+						t.Errorf("%s = %+v, want it left out when never tracked", name, got)
+					}
+					continue
+				}
+				if math.Abs(got.AveragePercent-want.AveragePercent) > 1e-9 || got.DaysBelowTarget != want.DaysBelowTarget || got.DaysLogged != want.DaysLogged {
+					t.Errorf("%s = %+v, want %+v", name, got, want)
+				}
+			}
+			if !slices.Equal(window.ChronicDeficiencies, test.wantChronic) {
+				t.Errorf("chronic = %v, want %v", window.ChronicDeficiencies, test.wantChronic)
+			}
+			if (len(window.Suggestions) > 0) != (len(test.wantChronic) > 0) {
+				t.Errorf("suggestions = %v, want some only for chronic deficiencies", window.Suggestions)
+			}
+		})
+	}
+}
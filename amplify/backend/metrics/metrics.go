@@ -0,0 +1,141 @@
+// The-Nutrimancers-Codex/amplify/backend/metrics/metrics.go
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry holds the service's collectors plus Go runtime and process metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "nutrimancer_http_requests_total",
+		Help: "HTTP requests by route pattern, method and status code.",
+	}, []string{"route", "method", "status"})
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "nutrimancer_http_request_duration_seconds",
+		Help:    "HTTP request latency by route pattern and method.",
+		Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"route", "method"})
+
+	upstreamRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "nutrimancer_upstream_requests_total",
+		Help: "Calls to upstream APIs (gemini, nutritionix, fdc) by outcome: success, or error for transport failures and non-2xx answers.",
+	}, []string{"upstream", "outcome"})
+	upstreamDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "nutrimancer_upstream_request_duration_seconds",
+		Help:    "Upstream API call latency.",
+		Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+	}, []string{"upstream"})
+
+	cacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "nutrimancer_lookup_cache_requests_total",
+		Help: "Nutrient lookups through the cache by tier (memory, disk, provider) and outcome (hit, negativeHit, miss).",
+	}, []string{"tier", "outcome"})
+
+	recommenderDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "nutrimancer_recommender_duration_seconds",
+		Help:    "Recommender execution time by kind (foods, complementaryProteins, trends).",
+		Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1},
+	}, []string{"kind"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		requests, requestDuration,
+		upstreamRequests, upstreamDuration,
+		cacheLookups,
+		recommenderDuration,
+	)
+}
+
+// Handler serves the registry in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+/*=================================================================================================*/
+
+// Instrument counts and times every request under the ServeMux pattern it matched,
+// so /v1/journal/entries/{id} is one series rather than one per ID
+func Instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		route := r.Pattern // set by the ServeMux on this request
+		if route == "" {
+			route = "unmatched"
+		}
+		requests.WithLabelValues(route, r.Method, strconv.Itoa(recorder.status)).Inc()
+		requestDuration.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap lets http.ResponseController reach Flush and SetWriteDeadline for streaming
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+/*=================================================================================================*/
+
+// Transport counts and times next's calls, labelled by the upstream each request names
+// (e.g. services.Upstream), so one shared client covers every upstream
+func Transport(next http.RoundTripper, upstream func(*http.Request) string) http.RoundTripper {
+	return upstreamTransport{upstream: upstream, next: next}
+}
+
+type upstreamTransport struct {
+	upstream func(*http.Request) string
+	next     http.RoundTripper
+}
+
+func (t upstreamTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	upstream := t.upstream(req)
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	upstreamDuration.WithLabelValues(upstream).Observe(time.Since(start).Seconds())
+
+	outcome := "success"
+	if err != nil || resp.StatusCode >= 300 {
+		outcome = "error"
+	}
+	upstreamRequests.WithLabelValues(upstream, outcome).Inc()
+	return resp, err
+}
+
+// ObserveCache counts one cached lookup, see cache.Options.Observe
+func ObserveCache(tier, outcome string) {
+	cacheLookups.WithLabelValues(tier, outcome).Inc()
+}
+
+// ObserveRecommender records how long one recommender run took
+func ObserveRecommender(kind string, start time.Time) {
+	recommenderDuration.WithLabelValues(kind).Observe(time.Since(start).Seconds())
+}
@@ -1,12 +1,83 @@
 // The-Nutrimancers-Codex/amplify/backend/models/model.go
 package models
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/derived"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/nutrients"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/units"
+)
+
+// SchemaVersion of ProcessFoodResponse; 2 added nutrientDetails
+const SchemaVersion = 2
+
 // Response Payload
 type ProcessFoodResponse struct {
-	Ingredients      []string                      `json:"ingredients"`
-	Nutrients        map[string]map[string]float64 `json:"nutrients"`
-	MissingNutrients []string                      `json:"missingNutrients"`
-	Suggestions      []string                      `json:"suggestions"`
+	SchemaVersion         int                                  `json:"schemaVersion"`
+	Ingredients           []string                             `json:"ingredients"`
+	Portions              map[string]Portion                   `json:"portions"`          // portion each ingredient was calculated for
+	Sources               map[string]FoodSource                `json:"sources,omitempty"` // which food each ingredient matched
+	Nutrients             map[string]map[string]float64        `json:"nutrients"`         // % of RDA/AI only, kept for v1 clients
+	NutrientDetails       map[string]map[string]NutrientAmount `json:"nutrientDetails"`
+	MissingNutrients      []string                             `json:"missingNutrients"`
+	UntrackedNutrients    []string                             `json:"untrackedNutrients"` // not tracked by source
+	Suggestions           []string                             `json:"suggestions"`
+	ComplementaryProteins []string                             `json:"complementaryProteins"` // for the meal's limiting amino acid
+	Gaps                  []NutrientGap                        `json:"gaps"`
+	ExcessNutrients       []ExcessNutrient                     `json:"excessNutrients"`
+	Macros                Macros                               `json:"macros"`
+	Derived               derived.Metrics                      `json:"derived"`
+	Profile               nutrients.Profile                    `json:"profile"`
+	Context               nutrients.MealContext                `json:"context"`
+}
+
+// One ingredient's amount of a nutrient, in the nutrient's registry unit
+type NutrientAmount struct {
+	Amount     float64    `json:"amount"`
+	Unit       units.Unit `json:"unit"`
+	Target     float64    `json:"target"`     // daily RDA/AI used for the percentage
+	Percentage float64    `json:"percentage"` // % of RDA/AI, uncapped
+}
+
+// Energy & macronutrients as absolute amounts (kcal, g) - Sugars target is an upper limit
+type Macros struct {
+	PerIngredient map[string]map[string]float64 `json:"perIngredient"`
+	Total         map[string]float64            `json:"total"`
+	Targets       map[string]float64            `json:"targets"`
+	Units         map[string]units.Unit         `json:"units"`
+}
+
+// Nutrient at or above its Tolerable Upper Intake Level
+type ExcessNutrient struct {
+	Nutrient          string             `json:"nutrient"`
+	Percentage        float64            `json:"percentage"`        // % of RDA/AI, uncapped
+	UpperLimitPercent float64            `json:"upperLimitPercent"` // % of UL
+	Severity          nutrients.Severity `json:"severity"`
+}
+
+type GapStatus string
+
+const (
+	GapMissing GapStatus = "missing" // no ingredient reported it
+	GapLow     GapStatus = "low"     // at or below the threshold
+	GapShort   GapStatus = "short"   // above the threshold but below the expectation
+	GapMet     GapStatus = "met"
+)
+
+// How a nutrient compares with the share of its daily target the meal context should cover
+type NutrientGap struct {
+	Nutrient         string    `json:"nutrient"`
+	Percentage       float64   `json:"percentage"`       // % of RDA/AI, uncapped
+	ExpectedPercent  float64   `json:"expectedPercent"`  // % of RDA/AI the context should cover
+	ThresholdPercent float64   `json:"thresholdPercent"` // at or below this counts as low
+	GapPercent       float64   `json:"gapPercent"`       // expected - actual, negative once met
+	Status           GapStatus `json:"status"`
 }
 
 type ErrorResponse struct {
@@ -15,9 +86,110 @@ type ErrorResponse struct {
 
 /*==================================================================================*/
 
+// Ingredient extracted from a food description; Quantity 0 means none was stated
+type Ingredient struct {
+	Food     string  `json:"food"`
+	Quantity float64 `json:"quantity"`
+	Unit     string  `json:"unit"`
+}
+
+// UnmarshalJSON accepts quantity as a number or a string - "2", "1.5", "1/2", "1 1/2" - since
+// the model doesn't always follow the prompt's types
+func (i *Ingredient) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Food     string          `json:"food"`
+		Quantity json.RawMessage `json:"quantity"`
+		Unit     string          `json:"unit"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*i = Ingredient{Food: raw.Food, Unit: raw.Unit}
+	if len(raw.Quantity) == 0 || string(raw.Quantity) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(raw.Quantity, &i.Quantity); err == nil {
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(raw.Quantity, &text); err != nil {
+		return fmt.Errorf("quantity %s is neither a number nor a string", raw.Quantity)
+	}
+	quantity, err := parseQuantity(text)
+	if err != nil {
+		return err
+	}
+	i.Quantity = quantity
+	return nil
+}
+
+// parseQuantity reads a decimal, a fraction or a whole number and a fraction; "" is 0
+func parseQuantity(text string) (float64, error) {
+	total := 0.0
+	for _, field := range strings.Fields(text) {
+		value, err := strconv.ParseFloat(field, 64)
+		if numerator, denominator, ok := strings.Cut(field, "/"); ok {
+			var n, d float64
+			n, err = strconv.ParseFloat(numerator, 64)
+			if err == nil {
+				d, err = strconv.ParseFloat(denominator, 64)
+			}
+			if err == nil && d == 0 {
+				err = errors.New("zero denominator")
+			}
+			value = n / d
+		}
+		if err != nil {
+			return 0, fmt.Errorf("quantity %q is not a number", text)
+		}
+		total += value
+	}
+	return total, nil
+}
+
+// Query is the ingredient as free text for a natural-language provider, e.g. "200 g rice"
+func (i Ingredient) Query() string {
+	if i.Quantity <= 0 {
+		return i.Food
+	}
+	parts := []string{strconv.FormatFloat(i.Quantity, 'g', -1, 64)}
+	if i.Unit != "" {
+		parts = append(parts, i.Unit)
+	}
+	return strings.Join(append(parts, i.Food), " ")
+}
+
+// Portion as stated, for when the provider didn't resolve one
+func (i Ingredient) Portion() Portion {
+	return Portion{Quantity: i.Quantity, Unit: i.Unit, Assumed: i.Quantity <= 0}
+}
+
+// Portion an ingredient's nutrients were calculated for
+type Portion struct {
+	Quantity float64 `json:"quantity"`
+	Unit     string  `json:"unit"`
+	Grams    float64 `json:"grams,omitempty"`
+	Assumed  bool    `json:"assumed"` // no quantity in the description - provider's default serving
+}
+
+// FoodSource is the provider's food an ingredient was matched to
+type FoodSource struct {
+	Provider    string  `json:"provider"`
+	ID          string  `json:"id,omitempty"` // e.g. the USDA fdc_id
+	Description string  `json:"description,omitempty"`
+	Confidence  float64 `json:"confidence,omitempty"` // 0-1, for fuzzy matches
+}
+
+/*==================================================================================*/
+
 // Payload Structure for Gemini API
 type GeminiRequest struct {
-	Contents []Content `json:"contents"`
+	Contents         []Content         `json:"contents"`
+	GenerationConfig *GenerationConfig `json:"generationConfig,omitempty"`
+}
+
+type GenerationConfig struct {
+	ResponseMimeType string `json:"responseMimeType,omitempty"` // "application/json" for structured output
 }
 
 type Content struct {
@@ -42,13 +214,196 @@ type CandidateContent struct {
 
 /*==================================================================================*/
 
-// Outgoing Responses
-type FoodResponse struct {
-	Ingredients      []string                      `json:"ingredients"`
-	Nutrients        map[string]map[string]float64 `json:"nutrients"`
-	MissingNutrients []string                      `json:"missingNutrients"`
-	Suggestions      []string                      `json:"suggestions"`
+// Request Payloads
+type ProcessFoodRequest struct {
+	FoodDescription string                `json:"foodDescription"`
+	Profile         *nutrients.Profile    `json:"profile,omitempty"`
+	Context         nutrients.MealContext `json:"context,omitempty"` // snack, breakfast, mainMeal (default), fullDay
+}
+
+// Many meals analyzed concurrently, e.g. an imported food diary
+type BatchProcessFoodRequest struct {
+	Items []ProcessFoodRequest `json:"items"`
+}
+
+// Results line up with the request's items; a failed item carries its own error and status
+type BatchProcessFoodResponse struct {
+	Results   []BatchItemResult `json:"results"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+}
+
+type BatchItemResult struct {
+	Index  int                  `json:"index"`
+	Result *ProcessFoodResponse `json:"result,omitempty"`
+	Error  string               `json:"error,omitempty"`
+	Status int                  `json:"status"` // what the single-meal endpoint would have answered
+}
+
+// Streamed analysis - Server-Sent Events in this order, or error at any point
+type AnalysisEvent string
+
+const (
+	EventIngredients     AnalysisEvent = "ingredients"
+	EventIngredient      AnalysisEvent = "ingredient" // one per ingredient, as each lookup returns
+	EventTotals          AnalysisEvent = "totals"
+	EventRecommendations AnalysisEvent = "recommendations"
+	EventResult          AnalysisEvent = "result" // the same ProcessFoodResponse as the plain endpoint
+	EventError           AnalysisEvent = "error"
+)
+
+type IngredientsExtracted struct {
+	Ingredients []string `json:"ingredients"`
+}
+
+type IngredientResolved struct {
+	Ingredient      string                    `json:"ingredient"`
+	Portion         Portion                   `json:"portion"`
+	Source          *FoodSource               `json:"source,omitempty"`
+	Nutrients       map[string]float64        `json:"nutrients"` // % of RDA/AI
+	NutrientDetails map[string]NutrientAmount `json:"nutrientDetails"`
+	Macros          map[string]float64        `json:"macros"`
+}
+
+type TotalsComputed struct {
+	Nutrients          map[string]float64 `json:"nutrients"` // % of RDA/AI, uncapped
+	MissingNutrients   []string           `json:"missingNutrients"`
+	UntrackedNutrients []string           `json:"untrackedNutrients"`
+	Gaps               []NutrientGap      `json:"gaps"`
+	ExcessNutrients    []ExcessNutrient   `json:"excessNutrients"`
+	Macros             Macros             `json:"macros"`
+}
+
+type RecommendationsReady struct {
+	Suggestions           []string `json:"suggestions"`
+	ComplementaryProteins []string `json:"complementaryProteins"`
+}
+
+// Sent instead of the remaining events; Status is what the plain endpoint would have answered
+type AnalysisFailed struct {
+	Error  string `json:"error"`
+	Status int    `json:"status"`
+}
+
+// Adds one suggested food to the current meal totals
+type FetchNutrientDataRequest struct {
+	FoodDescription  string             `json:"foodDescription"`
+	CurrentNutrients map[string]float64 `json:"currentNutrients"` // % of RDA/AI per nutrient
+	Profile          *nutrients.Profile `json:"profile,omitempty"`
+}
+
+type FetchNutrientDataResponse struct {
+	Nutrients        map[string]float64        `json:"nutrients"`
+	NutrientDetails  map[string]NutrientAmount `json:"nutrientDetails"` // the added food only
+	ChangedNutrients []string                  `json:"changedNutrients"`
+	ExcessNutrients  []ExcessNutrient          `json:"excessNutrients"`
+	Profile          nutrients.Profile         `json:"profile"`
+}
+
+/*==================================================================================*/
+
+// Meal journal
+
+type MealSlot string
+
+const (
+	SlotBreakfast MealSlot = "breakfast"
+	SlotLunch     MealSlot = "lunch"
+	SlotDinner    MealSlot = "dinner"
+	SlotSnack     MealSlot = "snack"
+)
+
+// DateLayout is how journal days are written in URLs and responses
+const DateLayout = "2006-01-02"
+
+// Resolve rejects unknown slots
+func (s MealSlot) Resolve() (MealSlot, error) {
+	switch s {
+	case SlotBreakfast, SlotLunch, SlotDinner, SlotSnack:
+		return s, nil
+	}
+	return "", fmt.Errorf("slot must be one of %q, %q, %q or %q, got %q", SlotBreakfast, SlotLunch, SlotDinner, SlotSnack, s)
+}
+
+// A saved analysis, as the user saw it. The day it counts towards is the date of Timestamp in
+// its own offset.
+type JournalEntry struct {
+	ID        string              `json:"id"`
+	Timestamp time.Time           `json:"timestamp"`
+	Slot      MealSlot            `json:"slot"`
+	Analysis  ProcessFoodResponse `json:"analysis"`
+}
+
+// Create or replace an entry; Timestamp defaults to now (create) or the saved time (edit)
+type JournalEntryRequest struct {
+	Timestamp *time.Time          `json:"timestamp,omitempty"`
+	Slot      MealSlot            `json:"slot"`
+	Analysis  ProcessFoodResponse `json:"analysis"` // as returned by the analysis endpoints
 }
-type FoodRequest struct {
-	FoodDescription string `json:"foodDescription"`
+
+// One day of journal entries, totalled with the same RDA logic as a single meal
+type DailyTotals struct {
+	Date             string             `json:"date"`
+	EntryIDs         []string           `json:"entryIds"`
+	Nutrients        map[string]float64 `json:"nutrients"` // % of RDA/AI, uncapped
+	MissingNutrients []string           `json:"missingNutrients"`
+	Gaps             []NutrientGap      `json:"gaps"` // against the full-day expectation
+	ExcessNutrients  []ExcessNutrient   `json:"excessNutrients"`
+	Macros           Macros             `json:"macros"` // perIngredient is keyed by entry ID
+	Profile          nutrients.Profile  `json:"profile"`
+}
+
+// Rolling averages - vitamin A, B12, D etc. only need to be met on average over a week
+
+// One nutrient across the logged days of a window. A day without entries is missing data rather
+// than a day of zero intake, so it's neither averaged in nor counted below target; DaysLogged
+// and the window's LoggedDays say how much of the window the figures rest on.
+type NutrientTrend struct {
+	AveragePercent  float64 `json:"averagePercent"`  // mean % of RDA/AI per logged day, not per day of the window
+	DaysBelowTarget int     `json:"daysBelowTarget"` // of DaysLogged
+	DaysLogged      int     `json:"daysLogged"`      // logged days the nutrient was tracked on
+}
+
+type TrendWindow struct {
+	Days                int                      `json:"days"`
+	Start               string                   `json:"start"`
+	End                 string                   `json:"end"`
+	LoggedDays          []string                 `json:"loggedDays"`
+	Nutrients           map[string]NutrientTrend `json:"nutrients"`
+	ChronicDeficiencies []string                 `json:"chronicDeficiencies"` // below target on most days and on average
+	Suggestions         []string                 `json:"suggestions"`         // recommender fed the chronic deficiencies
+}
+
+type NutrientTrends struct {
+	End     string        `json:"end"`
+	Windows []TrendWindow `json:"windows"` // 7 and 30 days ending at End
+}
+
+/*==================================================================================*/
+
+// Health & readiness - checks maps each dependency to "ok" or what's wrong with it
+type HealthStatus struct {
+	Status string            `json:"status"` // ok, ready or not ready
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+/*==================================================================================*/
+
+// Accounts
+
+type Credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type Account struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+// Send Token as "Authorization: Bearer <token>"
+type Session struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Account   Account   `json:"account"`
 }
@@ -0,0 +1,404 @@
+// The-Nutrimancers-Codex/amplify/backend/nutrients/dri.go
+package nutrients
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/units"
+)
+
+type Sex string
+
+const (
+	Male   Sex = "male"
+	Female Sex = "female"
+)
+
+// Profile selects the Dietary Reference Intake life-stage group used for targets
+type Profile struct {
+	Age       float64 `json:"age"`
+	Sex       Sex     `json:"sex,omitempty"`
+	Pregnant  bool    `json:"pregnant,omitempty"`
+	Lactating bool    `json:"lactating,omitempty"`
+	WeightKg  float64 `json:"weightKg,omitempty"` // amino acid targets are per kg
+	LifeStage string  `json:"lifeStage,omitempty"`
+}
+
+// DefaultProfile is used when a request carries no profile
+var DefaultProfile = Profile{Age: 30, Sex: Male}
+
+/*=================================================================================================*/
+
+// driRow holds one nutrient's RDA/AI across the DRI life-stage groups
+type driRow struct {
+	Unit      units.Unit // unit the published table uses
+	PerKg     bool       // value is per kg of body weight (amino acids)
+	Child     [2]float64 // 1-3, 4-8
+	Male      [6]float64 // 9-13, 14-18, 19-30, 31-50, 51-70, 71+
+	Female    [6]float64 // 9-13, 14-18, 19-30, 31-50, 51-70, 71+
+	Pregnancy [3]float64 // 14-18, 19-30, 31-50
+	Lactation [3]float64 // 14-18, 19-30, 31-50
+}
+
+// Reference body weights (kg) from the DRI reports, used when the profile has none
+var (
+	childWeights  = [2]float64{13, 23}
+	maleWeights   = [6]float64{40, 64, 70, 70, 70, 70}
+	femaleWeights = [6]float64{40, 56, 57, 57, 57, 57}
+)
+
+// Institute of Medicine / NASEM Dietary Reference Intakes (RDA, or AI where no RDA exists)
+var driTable = map[string]driRow{
+	// Ions
+	"Potassium": {Unit: "mg",
+		Child:     [2]float64{2000, 2300},
+		Male:      [6]float64{2500, 3000, 3400, 3400, 3400, 3400},
+		Female:    [6]float64{2300, 2300, 2600, 2600, 2600, 2600},
+		Pregnancy: [3]float64{2600, 2900, 2900}, Lactation: [3]float64{2500, 2800, 2800}},
+	"Chloride": {Unit: "mg",
+		Child:     [2]float64{1500, 1900},
+		Male:      [6]float64{2300, 2300, 2300, 2300, 2000, 1800},
+		Female:    [6]float64{2300, 2300, 2300, 2300, 2000, 1800},
+		Pregnancy: [3]float64{2300, 2300, 2300}, Lactation: [3]float64{2300, 2300, 2300}},
+	"Sodium": {Unit: "mg",
+		Child:     [2]float64{800, 1000},
+		Male:      [6]float64{1200, 1500, 1500, 1500, 1500, 1500},
+		Female:    [6]float64{1200, 1500, 1500, 1500, 1500, 1500},
+		Pregnancy: [3]float64{1500, 1500, 1500}, Lactation: [3]float64{1500, 1500, 1500}},
+	"Calcium": {Unit: "mg",
+		Child:     [2]float64{700, 1000},
+		Male:      [6]float64{1300, 1300, 1000, 1000, 1000, 1200},
+		Female:    [6]float64{1300, 1300, 1000, 1000, 1200, 1200},
+		Pregnancy: [3]float64{1300, 1000, 1000}, Lactation: [3]float64{1300, 1000, 1000}},
+	"Phosphorus": {Unit: "mg",
+		Child:     [2]float64{460, 500},
+		Male:      [6]float64{1250, 1250, 700, 700, 700, 700},
+		Female:    [6]float64{1250, 1250, 700, 700, 700, 700},
+		Pregnancy: [3]float64{1250, 700, 700}, Lactation: [3]float64{1250, 700, 700}},
+	"Magnesium": {Unit: "mg",
+		Child:     [2]float64{80, 130},
+		Male:      [6]float64{240, 410, 400, 420, 420, 420},
+		Female:    [6]float64{240, 360, 310, 320, 320, 320},
+		Pregnancy: [3]float64{400, 350, 360}, Lactation: [3]float64{360, 310, 320}},
+	"Iron": {Unit: "mg",
+		Child:     [2]float64{7, 10},
+		Male:      [6]float64{8, 11, 8, 8, 8, 8},
+		Female:    [6]float64{8, 15, 18, 18, 8, 8},
+		Pregnancy: [3]float64{27, 27, 27}, Lactation: [3]float64{10, 9, 9}},
+	"Zinc": {Unit: "mg",
+		Child:     [2]float64{3, 5},
+		Male:      [6]float64{8, 11, 11, 11, 11, 11},
+		Female:    [6]float64{8, 9, 8, 8, 8, 8},
+		Pregnancy: [3]float64{12, 11, 11}, Lactation: [3]float64{13, 12, 12}},
+	"Manganese": {Unit: "mg",
+		Child:     [2]float64{1.2, 1.5},
+		Male:      [6]float64{1.9, 2.2, 2.3, 2.3, 2.3, 2.3},
+		Female:    [6]float64{1.6, 1.6, 1.8, 1.8, 1.8, 1.8},
+		Pregnancy: [3]float64{2.0, 2.0, 2.0}, Lactation: [3]float64{2.6, 2.6, 2.6}},
+	"Copper": {Unit: "µg",
+		Child:     [2]float64{340, 440},
+		Male:      [6]float64{700, 890, 900, 900, 900, 900},
+		Female:    [6]float64{700, 890, 900, 900, 900, 900},
+		Pregnancy: [3]float64{1000, 1000, 1000}, Lactation: [3]float64{1300, 1300, 1300}},
+	"Selenium": {Unit: "µg",
+		Child:     [2]float64{20, 30},
+		Male:      [6]float64{40, 55, 55, 55, 55, 55},
+		Female:    [6]float64{40, 55, 55, 55, 55, 55},
+		Pregnancy: [3]float64{60, 60, 60}, Lactation: [3]float64{70, 70, 70}},
+	"Iodine": {Unit: "µg",
+		Child:     [2]float64{90, 90},
+		Male:      [6]float64{120, 150, 150, 150, 150, 150},
+		Female:    [6]float64{120, 150, 150, 150, 150, 150},
+		Pregnancy: [3]float64{220, 220, 220}, Lactation: [3]float64{290, 290, 290}},
+	"Chromium": {Unit: "µg",
+		Child:     [2]float64{11, 15},
+		Male:      [6]float64{25, 35, 35, 35, 30, 30},
+		Female:    [6]float64{21, 24, 25, 25, 20, 20},
+		Pregnancy: [3]float64{29, 30, 30}, Lactation: [3]float64{44, 45, 45}},
+	"Molybdenum": {Unit: "µg",
+		Child:     [2]float64{17, 22},
+		Male:      [6]float64{34, 43, 45, 45, 45, 45},
+		Female:    [6]float64{34, 43, 45, 45, 45, 45},
+		Pregnancy: [3]float64{50, 50, 50}, Lactation: [3]float64{50, 50, 50}},
+
+	// Essential Amino-Acids - Methionine is methionine + cysteine, Phenylalanine is phenylalanine + tyrosine
+	"Histidine": {Unit: units.Milligram, PerKg: true,
+		Child:     [2]float64{21, 16},
+		Male:      [6]float64{17, 15, 14, 14, 14, 14},
+		Female:    [6]float64{15, 14, 14, 14, 14, 14},
+		Pregnancy: [3]float64{18, 18, 18}, Lactation: [3]float64{19, 19, 19}},
+	"Isoleucine": {Unit: units.Milligram, PerKg: true,
+		Child:     [2]float64{28, 22},
+		Male:      [6]float64{22, 21, 19, 19, 19, 19},
+		Female:    [6]float64{21, 19, 19, 19, 19, 19},
+		Pregnancy: [3]float64{25, 25, 25}, Lactation: [3]float64{30, 30, 30}},
+	"Leucine": {Unit: units.Milligram, PerKg: true,
+		Child:     [2]float64{63, 49},
+		Male:      [6]float64{49, 47, 42, 42, 42, 42},
+		Female:    [6]float64{47, 44, 42, 42, 42, 42},
+		Pregnancy: [3]float64{56, 56, 56}, Lactation: [3]float64{62, 62, 62}},
+	"Lysine": {Unit: units.Milligram, PerKg: true,
+		Child:     [2]float64{58, 46},
+		Male:      [6]float64{46, 43, 38, 38, 38, 38},
+		Female:    [6]float64{43, 40, 38, 38, 38, 38},
+		Pregnancy: [3]float64{51, 51, 51}, Lactation: [3]float64{52, 52, 52}},
+	"Methionine": {Unit: units.Milligram, PerKg: true,
+		Child:     [2]float64{28, 22},
+		Male:      [6]float64{22, 21, 19, 19, 19, 19},
+		Female:    [6]float64{21, 19, 19, 19, 19, 19},
+		Pregnancy: [3]float64{25, 25, 25}, Lactation: [3]float64{26, 26, 26}},
+	"Phenylalanine": {Unit: units.Milligram, PerKg: true,
+		Child:     [2]float64{54, 41},
+		Male:      [6]float64{41, 38, 33, 33, 33, 33},
+		Female:    [6]float64{38, 35, 33, 33, 33, 33},
+		Pregnancy: [3]float64{44, 44, 44}, Lactation: [3]float64{51, 51, 51}},
+	"Threonine": {Unit: units.Milligram, PerKg: true,
+		Child:     [2]float64{32, 24},
+		Male:      [6]float64{24, 22, 20, 20, 20, 20},
+		Female:    [6]float64{22, 21, 20, 20, 20, 20},
+		Pregnancy: [3]float64{26, 26, 26}, Lactation: [3]float64{30, 30, 30}},
+	"Tryptophan": {Unit: units.Milligram, PerKg: true,
+		Child:     [2]float64{8, 6},
+		Male:      [6]float64{6, 6, 5, 5, 5, 5},
+		Female:    [6]float64{6, 5, 5, 5, 5, 5},
+		Pregnancy: [3]float64{7, 7, 7}, Lactation: [3]float64{9, 9, 9}},
+	"Valine": {Unit: units.Milligram, PerKg: true,
+		Child:     [2]float64{37, 28},
+		Male:      [6]float64{28, 27, 24, 24, 24, 24},
+		Female:    [6]float64{27, 24, 24, 24, 24, 24},
+		Pregnancy: [3]float64{31, 31, 31}, Lactation: [3]float64{35, 35, 35}},
+
+	// Essential Omega Fatty Acids - EPA/DHA have no DRI, EFSA long-chain omega-3 guidance instead
+	"Alpha-Linolenic Acid": {Unit: "g",
+		Child:     [2]float64{0.7, 0.9},
+		Male:      [6]float64{1.2, 1.6, 1.6, 1.6, 1.6, 1.6},
+		Female:    [6]float64{1.0, 1.1, 1.1, 1.1, 1.1, 1.1},
+		Pregnancy: [3]float64{1.4, 1.4, 1.4}, Lactation: [3]float64{1.3, 1.3, 1.3}},
+	"Linoleic Acid": {Unit: "g",
+		Child:     [2]float64{7, 10},
+		Male:      [6]float64{12, 16, 17, 17, 14, 14},
+		Female:    [6]float64{10, 11, 12, 12, 11, 11},
+		Pregnancy: [3]float64{13, 13, 13}, Lactation: [3]float64{13, 13, 13}},
+	"EPA": {Unit: "mg",
+		Child:     [2]float64{100, 100},
+		Male:      [6]float64{200, 250, 250, 250, 250, 250},
+		Female:    [6]float64{200, 250, 250, 250, 250, 250},
+		Pregnancy: [3]float64{250, 250, 250}, Lactation: [3]float64{250, 250, 250}},
+	"DHA": {Unit: "mg",
+		Child:     [2]float64{100, 100},
+		Male:      [6]float64{200, 250, 250, 250, 250, 250},
+		Female:    [6]float64{200, 250, 250, 250, 250, 250},
+		Pregnancy: [3]float64{450, 450, 450}, Lactation: [3]float64{450, 450, 450}},
+
+	// Vitamins
+	"Vitamin A": {Unit: "µg", // RAE
+		Child:     [2]float64{300, 400},
+		Male:      [6]float64{600, 900, 900, 900, 900, 900},
+		Female:    [6]float64{600, 700, 700, 700, 700, 700},
+		Pregnancy: [3]float64{750, 770, 770}, Lactation: [3]float64{1200, 1300, 1300}},
+	"Vitamin B1": {Unit: "mg",
+		Child:     [2]float64{0.5, 0.6},
+		Male:      [6]float64{0.9, 1.2, 1.2, 1.2, 1.2, 1.2},
+		Female:    [6]float64{0.9, 1.0, 1.1, 1.1, 1.1, 1.1},
+		Pregnancy: [3]float64{1.4, 1.4, 1.4}, Lactation: [3]float64{1.4, 1.4, 1.4}},
+	"Vitamin B2": {Unit: "mg",
+		Child:     [2]float64{0.5, 0.6},
+		Male:      [6]float64{0.9, 1.3, 1.3, 1.3, 1.3, 1.3},
+		Female:    [6]float64{0.9, 1.0, 1.1, 1.1, 1.1, 1.1},
+		Pregnancy: [3]float64{1.4, 1.4, 1.4}, Lactation: [3]float64{1.6, 1.6, 1.6}},
+	"Vitamin B3": {Unit: "mg",
+		Child:     [2]float64{6, 8},
+		Male:      [6]float64{12, 16, 16, 16, 16, 16},
+		Female:    [6]float64{12, 14, 14, 14, 14, 14},
+		Pregnancy: [3]float64{18, 18, 18}, Lactation: [3]float64{17, 17, 17}},
+	"Vitamin B5": {Unit: "mg",
+		Child:     [2]float64{2, 3},
+		Male:      [6]float64{4, 5, 5, 5, 5, 5},
+		Female:    [6]float64{4, 5, 5, 5, 5, 5},
+		Pregnancy: [3]float64{6, 6, 6}, Lactation: [3]float64{7, 7, 7}},
+	"Vitamin B6": {Unit: "mg",
+		Child:     [2]float64{0.5, 0.6},
+		Male:      [6]float64{1.0, 1.3, 1.3, 1.3, 1.7, 1.7},
+		Female:    [6]float64{1.0, 1.2, 1.3, 1.3, 1.5, 1.5},
+		Pregnancy: [3]float64{1.9, 1.9, 1.9}, Lactation: [3]float64{2.0, 2.0, 2.0}},
+	"Vitamin B7": {Unit: "µg", // Biotin
+		Child:     [2]float64{8, 12},
+		Male:      [6]float64{20, 25, 30, 30, 30, 30},
+		Female:    [6]float64{20, 25, 30, 30, 30, 30},
+		Pregnancy: [3]float64{30, 30, 30}, Lactation: [3]float64{35, 35, 35}},
+	"Vitamin B9": {Unit: "µg", // DFE
+		Child:     [2]float64{150, 200},
+		Male:      [6]float64{300, 400, 400, 400, 400, 400},
+		Female:    [6]float64{300, 400, 400, 400, 400, 400},
+		Pregnancy: [3]float64{600, 600, 600}, Lactation: [3]float64{500, 500, 500}},
+	"Vitamin B12": {Unit: "µg",
+		Child:     [2]float64{0.9, 1.2},
+		Male:      [6]float64{1.8, 2.4, 2.4, 2.4, 2.4, 2.4},
+		Female:    [6]float64{1.8, 2.4, 2.4, 2.4, 2.4, 2.4},
+		Pregnancy: [3]float64{2.6, 2.6, 2.6}, Lactation: [3]float64{2.8, 2.8, 2.8}},
+	"Vitamin C": {Unit: "mg",
+		Child:     [2]float64{15, 25},
+		Male:      [6]float64{45, 75, 90, 90, 90, 90},
+		Female:    [6]float64{45, 65, 75, 75, 75, 75},
+		Pregnancy: [3]float64{80, 85, 85}, Lactation: [3]float64{115, 120, 120}},
+	"Vitamin D": {Unit: "µg",
+		Child:     [2]float64{15, 15},
+		Male:      [6]float64{15, 15, 15, 15, 15, 20},
+		Female:    [6]float64{15, 15, 15, 15, 15, 20},
+		Pregnancy: [3]float64{15, 15, 15}, Lactation: [3]float64{15, 15, 15}},
+	"Vitamin E": {Unit: "mg", // alpha-tocopherol
+		Child:     [2]float64{6, 7},
+		Male:      [6]float64{11, 15, 15, 15, 15, 15},
+		Female:    [6]float64{11, 15, 15, 15, 15, 15},
+		Pregnancy: [3]float64{15, 15, 15}, Lactation: [3]float64{19, 19, 19}},
+	"Vitamin K": {Unit: "µg",
+		Child:     [2]float64{30, 55},
+		Male:      [6]float64{60, 75, 120, 120, 120, 120},
+		Female:    [6]float64{60, 75, 90, 90, 90, 90},
+		Pregnancy: [3]float64{75, 90, 90}, Lactation: [3]float64{75, 90, 90}},
+
+	"Choline": {Unit: "mg",
+		Child:     [2]float64{200, 250},
+		Male:      [6]float64{375, 550, 550, 550, 550, 550},
+		Female:    [6]float64{375, 400, 425, 425, 425, 425},
+		Pregnancy: [3]float64{450, 450, 450}, Lactation: [3]float64{550, 550, 550}},
+}
+
+/*=================================================================================================*/
+
+// Resolve validates the profile and fills in the reference weight and life-stage label
+func (p Profile) Resolve() (Profile, error) {
+	if p.Age == 0 && p.Sex == "" && !p.Pregnant && !p.Lactating {
+		p.Sex, p.Age = DefaultProfile.Sex, DefaultProfile.Age
+	}
+	if p.Age < 1 || p.Age > 120 {
+		return Profile{}, fmt.Errorf("profile age must be between 1 and 120, got %g", p.Age)
+	}
+	if p.Sex != "" && p.Sex != Male && p.Sex != Female {
+		return Profile{}, fmt.Errorf("profile sex must be %q or %q, got %q", Male, Female, p.Sex)
+	}
+	if p.Age >= 9 && p.Sex == "" {
+		return Profile{}, errors.New("profile sex is required from age 9")
+	}
+	if p.Pregnant && p.Lactating {
+		return Profile{}, errors.New("profile cannot be both pregnant and lactating")
+	}
+	if (p.Pregnant || p.Lactating) && (p.Sex != Female || p.Age < 14 || p.Age > 50) {
+		return Profile{}, errors.New("pregnancy and lactation targets exist only for females aged 14-50")
+	}
+	if p.WeightKg < 0 {
+		return Profile{}, fmt.Errorf("profile weight must be positive, got %g", p.WeightKg)
+	}
+
+	if p.WeightKg == 0 {
+		p.WeightKg = p.referenceWeight()
+	}
+	p.LifeStage = p.lifeStage()
+	return p, nil
+}
+
+// Targets returns the daily target in mg for every registered nutrient
+func (p Profile) Targets() map[string]float64 {
+	targets := make(map[string]float64, len(registry))
+	for _, n := range registry {
+		if target, ok := p.Target(n.Name); ok {
+			targets[n.Name] = target
+		}
+	}
+	return targets
+}
+
+// Target returns the daily target in mg for one nutrient; the profile must be resolved
+func (p Profile) Target(name string) (float64, bool) {
+	row, ok := driTable[name]
+	if !ok {
+		return 0, false
+	}
+	value := row.pick(p)
+	if row.PerKg {
+		value *= p.WeightKg
+	}
+	target, err := units.ToMilligrams(name, units.Quantity{Value: value, Unit: row.Unit})
+	if err != nil {
+		return 0, false
+	}
+	return target, true
+}
+
+func (row driRow) pick(p Profile) float64 {
+	switch {
+	case p.Pregnant:
+		return row.Pregnancy[reproductiveBand(p.Age)]
+	case p.Lactating:
+		return row.Lactation[reproductiveBand(p.Age)]
+	case p.Age < 9:
+		return row.Child[childBand(p.Age)]
+	case p.Sex == Female:
+		return row.Female[adultBand(p.Age)]
+	default:
+		return row.Male[adultBand(p.Age)]
+	}
+}
+
+func (p Profile) referenceWeight() float64 {
+	switch {
+	case p.Age < 9:
+		return childWeights[childBand(p.Age)]
+	case p.Sex == Female:
+		return femaleWeights[adultBand(p.Age)]
+	default:
+		return maleWeights[adultBand(p.Age)]
+	}
+}
+
+func (p Profile) lifeStage() string {
+	bands := [6]string{"9-13", "14-18", "19-30", "31-50", "51-70", "71+"}
+	switch {
+	case p.Pregnant:
+		return fmt.Sprintf("pregnancy %s", bands[reproductiveBand(p.Age)+1])
+	case p.Lactating:
+		return fmt.Sprintf("lactation %s", bands[reproductiveBand(p.Age)+1])
+	case p.Age < 9:
+		return fmt.Sprintf("child %s", [2]string{"1-3", "4-8"}[childBand(p.Age)])
+	default:
+		return fmt.Sprintf("%s %s", p.Sex, bands[adultBand(p.Age)])
+	}
+}
+
+/*=================================================================================================*/
+
+func childBand(age float64) int {
+	if age < 4 {
+		return 0
+	}
+	return 1
+}
+
+func adultBand(age float64) int {
+	switch {
+	case age < 14:
+		return 0
+	case age < 19:
+		return 1
+	case age < 31:
+		return 2
+	case age < 51:
+		return 3
+	case age < 71:
+		return 4
+	default:
+		return 5
+	}
+}
+
+func reproductiveBand(age float64) int {
+	switch {
+	case age < 19:
+		return 0
+	case age < 31:
+		return 1
+	default:
+		return 2
+	}
+}
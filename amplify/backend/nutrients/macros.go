@@ -0,0 +1,63 @@
+// The-Nutrimancers-Codex/amplify/backend/nutrients/macros.go
+package nutrients
+
+import "github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/units"
+
+// Macronutrient reference targets - same life-stage layout as driTable.
+// Energy follows the Dietary Guidelines (moderately active), Fat is 30% of that energy,
+// and Sugars is a LIMIT (10% of energy), not a target.
+var macroTable = map[string]driRow{
+	"Energy": {Unit: "kcal",
+		Child:     [2]float64{1000, 1400},
+		Male:      [6]float64{2000, 2800, 2800, 2600, 2400, 2200},
+		Female:    [6]float64{1800, 2000, 2200, 2000, 1800, 1800},
+		Pregnancy: [3]float64{2350, 2550, 2350}, Lactation: [3]float64{2400, 2600, 2400}},
+	"Protein": {Unit: "g", PerKg: true, // RDA g/kg
+		Child:     [2]float64{1.05, 0.95},
+		Male:      [6]float64{0.95, 0.85, 0.8, 0.8, 0.8, 0.8},
+		Female:    [6]float64{0.95, 0.85, 0.8, 0.8, 0.8, 0.8},
+		Pregnancy: [3]float64{1.1, 1.1, 1.1}, Lactation: [3]float64{1.3, 1.3, 1.3}},
+	"Carbohydrate": {Unit: "g",
+		Child:     [2]float64{130, 130},
+		Male:      [6]float64{130, 130, 130, 130, 130, 130},
+		Female:    [6]float64{130, 130, 130, 130, 130, 130},
+		Pregnancy: [3]float64{175, 175, 175}, Lactation: [3]float64{210, 210, 210}},
+	"Fat": {Unit: "g",
+		Child:     [2]float64{33, 47},
+		Male:      [6]float64{67, 93, 93, 87, 80, 73},
+		Female:    [6]float64{60, 67, 73, 67, 60, 60},
+		Pregnancy: [3]float64{78, 85, 78}, Lactation: [3]float64{80, 87, 80}},
+	"Fiber": {Unit: "g",
+		Child:     [2]float64{19, 25},
+		Male:      [6]float64{31, 38, 38, 38, 30, 30},
+		Female:    [6]float64{26, 26, 25, 25, 21, 21},
+		Pregnancy: [3]float64{28, 28, 28}, Lactation: [3]float64{29, 29, 29}},
+	"Sugars": {Unit: "g",
+		Child:     [2]float64{25, 35},
+		Male:      [6]float64{50, 70, 70, 65, 60, 55},
+		Female:    [6]float64{45, 50, 55, 50, 45, 45},
+		Pregnancy: [3]float64{59, 64, 59}, Lactation: [3]float64{60, 65, 60}},
+}
+
+/*=================================================================================================*/
+
+// MacroTargets returns macronutrient targets in each nutrient's registry unit (kcal, g)
+func (p Profile) MacroTargets() map[string]float64 {
+	targets := make(map[string]float64, len(macroTable))
+	for name, row := range macroTable {
+		n, ok := byName[name]
+		if !ok {
+			continue
+		}
+		value := row.pick(p)
+		if row.PerKg {
+			value *= p.WeightKg
+		}
+		target, err := units.Convert(name, units.Quantity{Value: value, Unit: row.Unit}, n.Unit)
+		if err != nil {
+			continue
+		}
+		targets[name] = target.Value
+	}
+	return targets
+}
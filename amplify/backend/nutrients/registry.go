@@ -0,0 +1,242 @@
+// The-Nutrimancers-Codex/amplify/backend/nutrients/registry.go
+package nutrients
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/units"
+)
+
+type Category string
+
+const (
+	Ions       Category = "Ions"
+	AminoAcids Category = "Essential Amino-Acids"
+	FattyAcids Category = "Essential Omega Fatty Acids"
+	Vitamins   Category = "Vitamins"
+	Other      Category = "Other"
+	Macros     Category = "Macronutrients"
+)
+
+// Nutrient is the single definition of a tracked nutrient
+type Nutrient struct {
+	Name          string     // canonical name used in every response
+	Category      Category   // grouping used by the frontend orbs
+	Unit          units.Unit // unit the providers report amounts in
+	NutritionixID int        // Nutritionix full_nutrients attr_id
+	USDANumber    string     // USDA nutrient_nbr (data/LegacyNutrient.csv)
+	DatasetColumn string     // header in machinist/dataset.csv
+	Sparse        bool       // sources rarely report it - absent means "not tracked by source", not zero
+}
+
+/*=================================================================================================*/
+
+// Registry - ordered as the generated dataset columns. Add a nutrient HERE and nowhere else.
+var registry = []Nutrient{
+	// Ions
+	{Name: "Potassium", Category: Ions, Unit: "mg", NutritionixID: 306, USDANumber: "306", DatasetColumn: "Potassium"},
+	{Name: "Chloride", Category: Ions, Unit: "mg", NutritionixID: 302, USDANumber: "302", DatasetColumn: "Chloride", Sparse: true},
+	{Name: "Sodium", Category: Ions, Unit: "mg", NutritionixID: 307, USDANumber: "307", DatasetColumn: "Sodium"},
+	{Name: "Calcium", Category: Ions, Unit: "mg", NutritionixID: 301, USDANumber: "301", DatasetColumn: "Calcium"},
+	{Name: "Phosphorus", Category: Ions, Unit: "mg", NutritionixID: 305, USDANumber: "305", DatasetColumn: "Phosphorus"},
+	{Name: "Magnesium", Category: Ions, Unit: "mg", NutritionixID: 304, USDANumber: "304", DatasetColumn: "Magnesium"},
+	{Name: "Iron", Category: Ions, Unit: "mg", NutritionixID: 303, USDANumber: "303", DatasetColumn: "Iron"},
+	{Name: "Zinc", Category: Ions, Unit: "mg", NutritionixID: 309, USDANumber: "309", DatasetColumn: "Zinc"},
+	{Name: "Manganese", Category: Ions, Unit: "mg", NutritionixID: 315, USDANumber: "315", DatasetColumn: "Manganese"},
+	{Name: "Copper", Category: Ions, Unit: "mg", NutritionixID: 312, USDANumber: "312", DatasetColumn: "Copper"},
+	{Name: "Selenium", Category: Ions, Unit: "µg", NutritionixID: 317, USDANumber: "317", DatasetColumn: "Selenium"},
+	{Name: "Iodine", Category: Ions, Unit: "µg", NutritionixID: 314, USDANumber: "314", DatasetColumn: "Iodine", Sparse: true},
+	{Name: "Chromium", Category: Ions, Unit: "µg", NutritionixID: 310, USDANumber: "310", DatasetColumn: "Chromium", Sparse: true},
+	{Name: "Molybdenum", Category: Ions, Unit: "µg", NutritionixID: 316, USDANumber: "316", DatasetColumn: "Molybdenum", Sparse: true},
+
+	// Essential Amino-Acids
+	{Name: "Histidine", Category: AminoAcids, Unit: "g", NutritionixID: 512, USDANumber: "512", DatasetColumn: "Histidine"},
+	{Name: "Isoleucine", Category: AminoAcids, Unit: "g", NutritionixID: 503, USDANumber: "503", DatasetColumn: "Isoleucine"},
+	{Name: "Leucine", Category: AminoAcids, Unit: "g", NutritionixID: 504, USDANumber: "504", DatasetColumn: "Leucine"},
+	{Name: "Lysine", Category: AminoAcids, Unit: "g", NutritionixID: 505, USDANumber: "505", DatasetColumn: "Lysine"},
+	{Name: "Methionine", Category: AminoAcids, Unit: "g", NutritionixID: 506, USDANumber: "506", DatasetColumn: "Methionine"},
+	{Name: "Phenylalanine", Category: AminoAcids, Unit: "g", NutritionixID: 508, USDANumber: "508", DatasetColumn: "Phenylalanine"},
+	{Name: "Threonine", Category: AminoAcids, Unit: "g", NutritionixID: 502, USDANumber: "502", DatasetColumn: "Threonine"},
+	{Name: "Tryptophan", Category: AminoAcids, Unit: "g", NutritionixID: 501, USDANumber: "501", DatasetColumn: "Tryptophan"},
+	{Name: "Valine", Category: AminoAcids, Unit: "g", NutritionixID: 510, USDANumber: "510", DatasetColumn: "Valine"},
+
+	// Essential Omega Fatty Acids
+	{Name: "Alpha-Linolenic Acid", Category: FattyAcids, Unit: "g", NutritionixID: 851, USDANumber: "851", DatasetColumn: "Alpha-Linolenic Acid"}, // Plant Omega-3
+	{Name: "Linoleic Acid", Category: FattyAcids, Unit: "g", NutritionixID: 675, USDANumber: "675", DatasetColumn: "Linoleic Acid"},               // Omega-6
+	{Name: "EPA", Category: FattyAcids, Unit: "g", NutritionixID: 629, USDANumber: "629", DatasetColumn: "EPA"},                                   // Omega-3 fish oil
+	{Name: "DHA", Category: FattyAcids, Unit: "g", NutritionixID: 621, USDANumber: "621", DatasetColumn: "DHA"},                                   // Omega-3 fish oil
+
+	// Vitamins
+	{Name: "Vitamin A", Category: Vitamins, Unit: "µg", NutritionixID: 320, USDANumber: "320", DatasetColumn: "Vitamin A"}, // RAE
+	{Name: "Vitamin B1", Category: Vitamins, Unit: "mg", NutritionixID: 404, USDANumber: "404", DatasetColumn: "Vitamin B1"},
+	{Name: "Vitamin B2", Category: Vitamins, Unit: "mg", NutritionixID: 405, USDANumber: "405", DatasetColumn: "Vitamin B2"},
+	{Name: "Vitamin B3", Category: Vitamins, Unit: "mg", NutritionixID: 406, USDANumber: "406", DatasetColumn: "Vitamin B3"},
+	{Name: "Vitamin B5", Category: Vitamins, Unit: "mg", NutritionixID: 410, USDANumber: "410", DatasetColumn: "Vitamin B5"},
+	{Name: "Vitamin B6", Category: Vitamins, Unit: "mg", NutritionixID: 415, USDANumber: "415", DatasetColumn: "Vitamin B6"},
+	{Name: "Vitamin B7", Category: Vitamins, Unit: "µg", NutritionixID: 416, USDANumber: "416", DatasetColumn: "Vitamin B7", Sparse: true}, // Biotin
+	{Name: "Vitamin B9", Category: Vitamins, Unit: "µg", NutritionixID: 417, USDANumber: "417", DatasetColumn: "Vitamin B9"},               // Folate
+	{Name: "Vitamin B12", Category: Vitamins, Unit: "µg", NutritionixID: 418, USDANumber: "418", DatasetColumn: "Vitamin B12"},
+	{Name: "Vitamin C", Category: Vitamins, Unit: "mg", NutritionixID: 401, USDANumber: "401", DatasetColumn: "Vitamin C"},
+	{Name: "Vitamin D", Category: Vitamins, Unit: "IU", NutritionixID: 324, USDANumber: "328", DatasetColumn: "Vitamin D"}, // Nutritionix 324 is IU, USDA 328 is µg
+	{Name: "Vitamin E", Category: Vitamins, Unit: "mg", NutritionixID: 323, USDANumber: "323", DatasetColumn: "Vitamin E"}, // alpha-tocopherol
+	{Name: "Vitamin K", Category: Vitamins, Unit: "µg", NutritionixID: 430, USDANumber: "430", DatasetColumn: "Vitamin K"},
+
+	{Name: "Choline", Category: Other, Unit: "mg", NutritionixID: 421, USDANumber: "421", DatasetColumn: "Choline"},
+
+	// Macronutrients - reported in the macros block, not as RDA percentages; optional dataset columns
+	{Name: "Energy", Category: Macros, Unit: "kcal", NutritionixID: 208, USDANumber: "208", DatasetColumn: "Energy"},
+	{Name: "Protein", Category: Macros, Unit: "g", NutritionixID: 203, USDANumber: "203", DatasetColumn: "Protein"},
+	{Name: "Carbohydrate", Category: Macros, Unit: "g", NutritionixID: 205, USDANumber: "205", DatasetColumn: "Carbohydrate"},
+	{Name: "Fat", Category: Macros, Unit: "g", NutritionixID: 204, USDANumber: "204", DatasetColumn: "Fat"},
+	{Name: "Fiber", Category: Macros, Unit: "g", NutritionixID: 291, USDANumber: "291", DatasetColumn: "Fiber"},
+	{Name: "Sugars", Category: Macros, Unit: "g", NutritionixID: 269, USDANumber: "269", DatasetColumn: "Sugars"},
+}
+
+var byName = func() map[string]Nutrient {
+	m := make(map[string]Nutrient, len(registry))
+	for _, n := range registry {
+		m[n.Name] = n
+	}
+	return m
+}()
+
+/*=================================================================================================*/
+
+// All returns every registered nutrient in dataset column order
+func All() []Nutrient {
+	all := make([]Nutrient, len(registry))
+	copy(all, registry)
+	return all
+}
+
+// Names returns the micronutrient names tracked against RDA, in dataset column order
+func Names() []string {
+	var names []string
+	for _, n := range registry {
+		if !n.IsMacro() {
+			names = append(names, n.Name)
+		}
+	}
+	return names
+}
+
+// MacroNames returns the macronutrient names in dataset column order
+func MacroNames() []string {
+	var names []string
+	for _, n := range registry {
+		if n.IsMacro() {
+			names = append(names, n.Name)
+		}
+	}
+	return names
+}
+
+func (n Nutrient) IsMacro() bool {
+	return n.Category == Macros
+}
+
+// Lookup finds a nutrient by canonical name
+func Lookup(name string) (Nutrient, bool) {
+	n, ok := byName[name]
+	return n, ok
+}
+
+// ByDatasetColumn finds a nutrient by its dataset header
+func ByDatasetColumn(column string) (Nutrient, bool) {
+	for _, n := range registry {
+		if n.DatasetColumn == column {
+			return n, true
+		}
+	}
+	return Nutrient{}, false
+}
+
+/*=================================================================================================*/
+
+// Validate checks the registry for duplicate or incomplete definitions
+func Validate() error {
+	var problems []string
+	seenNbr := make(map[string]string)
+	seenColumn := make(map[string]string)
+
+	if len(byName) != len(registry) {
+		problems = append(problems, "duplicate nutrient names")
+	}
+	for _, n := range registry {
+		if n.IsMacro() {
+			if _, ok := macroTable[n.Name]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: no reference target", n.Name))
+			}
+			if _, err := units.Parse(string(n.Unit)); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", n.Name, err))
+			}
+		} else {
+			if _, ok := driTable[n.Name]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: no DRI targets", n.Name))
+			}
+			if _, err := units.ToMilligrams(n.Name, units.Quantity{Value: 1, Unit: n.Unit}); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", n.Name, err))
+			}
+		}
+		if other, dup := seenNbr[n.USDANumber]; dup && n.USDANumber != "" {
+			problems = append(problems, fmt.Sprintf("%s: USDA nutrient number %s already used by %s", n.Name, n.USDANumber, other))
+		}
+		seenNbr[n.USDANumber] = n.Name
+		if n.DatasetColumn == "" {
+			problems = append(problems, fmt.Sprintf("%s: missing dataset column", n.Name))
+		} else if other, dup := seenColumn[n.DatasetColumn]; dup {
+			problems = append(problems, fmt.Sprintf("%s: dataset column %q already used by %s", n.Name, n.DatasetColumn, other))
+		}
+		seenColumn[n.DatasetColumn] = n.Name
+	}
+
+	for name := range driTable {
+		if _, ok := byName[name]; !ok {
+			problems = append(problems, fmt.Sprintf("DRI targets for unregistered nutrient %s", name))
+		}
+	}
+	for name := range macroTable {
+		if n, ok := byName[name]; !ok || !n.IsMacro() {
+			problems = append(problems, fmt.Sprintf("reference target for unregistered macronutrient %s", name))
+		}
+	}
+	for name := range ulTable {
+		if _, ok := byName[name]; !ok {
+			problems = append(problems, fmt.Sprintf("UL for unregistered nutrient %s", name))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("nutrient registry: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// ValidateDatasetHeader checks dataset nutrient columns against the registry, both ways.
+// Macronutrient and sparse columns are optional until dataset.csv is regenerated with them.
+func ValidateDatasetHeader(columns []string) error {
+	var problems []string
+	present := make(map[string]bool, len(columns))
+	known := make(map[string]bool, len(registry))
+
+	for _, column := range columns {
+		present[column] = true
+	}
+	for _, n := range registry {
+		known[n.DatasetColumn] = true
+		if !present[n.DatasetColumn] && !n.IsMacro() && !n.Sparse {
+			problems = append(problems, fmt.Sprintf("missing column %q for %s", n.DatasetColumn, n.Name))
+		}
+	}
+	for _, column := range columns {
+		if !known[column] {
+			problems = append(problems, fmt.Sprintf("unregistered column %q", column))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("dataset header disagrees with nutrient registry: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
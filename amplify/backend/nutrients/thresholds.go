@@ -0,0 +1,99 @@
+// The-Nutrimancers-Codex/amplify/backend/nutrients/thresholds.go
+package nutrients
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// MealContext says how much of the day the described food is meant to cover
+type MealContext string
+
+const (
+	Snack     MealContext = "snack"
+	Breakfast MealContext = "breakfast"
+	MainMeal  MealContext = "mainMeal"
+	FullDay   MealContext = "fullDay"
+)
+
+// DefaultMealContext is used when a request carries no context
+const DefaultMealContext = MainMeal
+
+// Expected share of the daily targets for each context
+var expectedShares = map[MealContext]float64{
+	Snack:     0.10,
+	Breakfast: 0.25,
+	MainMeal:  0.35,
+	FullDay:   1.00,
+}
+
+// Resolve fills in the default context and rejects unknown ones
+func (c MealContext) Resolve() (MealContext, error) {
+	if c == "" {
+		return DefaultMealContext, nil
+	}
+	if _, ok := expectedShares[c]; !ok {
+		return "", fmt.Errorf("context must be one of %q, %q, %q or %q, got %q", Snack, Breakfast, MainMeal, FullDay, c)
+	}
+	return c, nil
+}
+
+// ExpectedPercent is the share of the daily target this context should cover, in %
+func (c MealContext) ExpectedPercent() float64 {
+	return expectedShares[c] * 100
+}
+
+/*=================================================================================================*/
+
+// Thresholds decide when a nutrient counts as low, as a fraction of the context's expectation.
+// 0.1 in a main meal flags anything at or below 3.5% of the daily target.
+type Thresholds struct {
+	Default   float64            `json:"default"`
+	Nutrients map[string]float64 `json:"nutrients,omitempty"` // per-nutrient overrides
+}
+
+// DefaultThresholds is used when no thresholds file is configured
+var DefaultThresholds = Thresholds{Default: 0.1}
+
+// LoadThresholds reads a thresholds JSON file and validates it against the registry
+func LoadThresholds(path string) (Thresholds, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Thresholds{}, err
+	}
+	var thresholds Thresholds
+	if err := json.Unmarshal(data, &thresholds); err != nil {
+		return Thresholds{}, fmt.Errorf("%s: %v", path, err)
+	}
+	if err := thresholds.Validate(); err != nil {
+		return Thresholds{}, fmt.Errorf("%s: %v", path, err)
+	}
+	return thresholds, nil
+}
+
+// Validate checks fractions lie in 0-1 and overrides name registered micronutrients
+func (t Thresholds) Validate() error {
+	if t.Default < 0 || t.Default > 1 {
+		return fmt.Errorf("default threshold must be between 0 and 1, got %g", t.Default)
+	}
+	for name, fraction := range t.Nutrients {
+		n, ok := byName[name]
+		if !ok || n.IsMacro() {
+			return fmt.Errorf("threshold for unknown micronutrient %q", name)
+		}
+		if fraction < 0 || fraction > 1 {
+			return fmt.Errorf("threshold for %s must be between 0 and 1, got %g", name, fraction)
+		}
+	}
+	return nil
+}
+
+// LowPercent is the % of daily target at or below which a nutrient is low in this context
+func (t Thresholds) LowPercent(name string, mealContext MealContext) float64 {
+	fraction, ok := t.Nutrients[name]
+	if !ok {
+		fraction = t.Default
+	}
+	return fraction * mealContext.ExpectedPercent()
+}
@@ -0,0 +1,129 @@
+// The-Nutrimancers-Codex/amplify/backend/nutrients/ul.go
+package nutrients
+
+import "github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/units"
+
+type Severity string
+
+const (
+	Approaching Severity = "approaching" // 80-100% of the UL
+	Exceeded    Severity = "exceeded"    // 100-200% of the UL
+	Severe      Severity = "severe"      // 200%+ of the UL
+)
+
+// Tolerable Upper Intake Levels - same life-stage layout as driTable, 0 = no UL established.
+// Sodium uses the Chronic Disease Risk Reduction intake. Magnesium, niacin (B3), folate (B9) and
+// vitamin E ULs only cover supplements and fortificants, and vitamin A's only preformed retinol,
+// so they're left out: against total food intake they'd flag ordinary meals.
+var ulTable = map[string]driRow{
+	// Ions
+	"Chloride": {Unit: "mg",
+		Child:     [2]float64{2300, 2900},
+		Male:      [6]float64{3400, 3600, 3600, 3600, 3600, 3600},
+		Female:    [6]float64{3400, 3600, 3600, 3600, 3600, 3600},
+		Pregnancy: [3]float64{3600, 3600, 3600}, Lactation: [3]float64{3600, 3600, 3600}},
+	"Sodium": {Unit: "mg",
+		Child:     [2]float64{1200, 1500},
+		Male:      [6]float64{1800, 2300, 2300, 2300, 2300, 2300},
+		Female:    [6]float64{1800, 2300, 2300, 2300, 2300, 2300},
+		Pregnancy: [3]float64{2300, 2300, 2300}, Lactation: [3]float64{2300, 2300, 2300}},
+	"Calcium": {Unit: "mg",
+		Child:     [2]float64{2500, 2500},
+		Male:      [6]float64{3000, 3000, 2500, 2500, 2000, 2000},
+		Female:    [6]float64{3000, 3000, 2500, 2500, 2000, 2000},
+		Pregnancy: [3]float64{3000, 2500, 2500}, Lactation: [3]float64{3000, 2500, 2500}},
+	"Phosphorus": {Unit: "mg",
+		Child:     [2]float64{3000, 3000},
+		Male:      [6]float64{4000, 4000, 4000, 4000, 4000, 3000},
+		Female:    [6]float64{4000, 4000, 4000, 4000, 4000, 3000},
+		Pregnancy: [3]float64{3500, 3500, 3500}, Lactation: [3]float64{4000, 4000, 4000}},
+	"Iron": {Unit: "mg",
+		Child:     [2]float64{40, 40},
+		Male:      [6]float64{40, 45, 45, 45, 45, 45},
+		Female:    [6]float64{40, 45, 45, 45, 45, 45},
+		Pregnancy: [3]float64{45, 45, 45}, Lactation: [3]float64{45, 45, 45}},
+	"Zinc": {Unit: "mg",
+		Child:     [2]float64{7, 12},
+		Male:      [6]float64{23, 34, 40, 40, 40, 40},
+		Female:    [6]float64{23, 34, 40, 40, 40, 40},
+		Pregnancy: [3]float64{34, 40, 40}, Lactation: [3]float64{34, 40, 40}},
+	"Manganese": {Unit: "mg",
+		Child:     [2]float64{2, 3},
+		Male:      [6]float64{6, 9, 11, 11, 11, 11},
+		Female:    [6]float64{6, 9, 11, 11, 11, 11},
+		Pregnancy: [3]float64{9, 11, 11}, Lactation: [3]float64{9, 11, 11}},
+	"Copper": {Unit: "µg",
+		Child:     [2]float64{1000, 3000},
+		Male:      [6]float64{5000, 8000, 10000, 10000, 10000, 10000},
+		Female:    [6]float64{5000, 8000, 10000, 10000, 10000, 10000},
+		Pregnancy: [3]float64{8000, 10000, 10000}, Lactation: [3]float64{8000, 10000, 10000}},
+	"Selenium": {Unit: "µg",
+		Child:     [2]float64{90, 150},
+		Male:      [6]float64{280, 400, 400, 400, 400, 400},
+		Female:    [6]float64{280, 400, 400, 400, 400, 400},
+		Pregnancy: [3]float64{400, 400, 400}, Lactation: [3]float64{400, 400, 400}},
+	"Iodine": {Unit: "µg",
+		Child:     [2]float64{200, 300},
+		Male:      [6]float64{600, 900, 1100, 1100, 1100, 1100},
+		Female:    [6]float64{600, 900, 1100, 1100, 1100, 1100},
+		Pregnancy: [3]float64{900, 1100, 1100}, Lactation: [3]float64{900, 1100, 1100}},
+	"Molybdenum": {Unit: "µg",
+		Child:     [2]float64{300, 600},
+		Male:      [6]float64{1100, 1700, 2000, 2000, 2000, 2000},
+		Female:    [6]float64{1100, 1700, 2000, 2000, 2000, 2000},
+		Pregnancy: [3]float64{1700, 2000, 2000}, Lactation: [3]float64{1700, 2000, 2000}},
+
+	// Vitamins
+	"Vitamin B6": {Unit: "mg",
+		Child:     [2]float64{30, 40},
+		Male:      [6]float64{60, 80, 100, 100, 100, 100},
+		Female:    [6]float64{60, 80, 100, 100, 100, 100},
+		Pregnancy: [3]float64{80, 100, 100}, Lactation: [3]float64{80, 100, 100}},
+	"Vitamin C": {Unit: "mg",
+		Child:     [2]float64{400, 650},
+		Male:      [6]float64{1200, 1800, 2000, 2000, 2000, 2000},
+		Female:    [6]float64{1200, 1800, 2000, 2000, 2000, 2000},
+		Pregnancy: [3]float64{1800, 2000, 2000}, Lactation: [3]float64{1800, 2000, 2000}},
+	"Vitamin D": {Unit: "µg",
+		Child:     [2]float64{63, 75},
+		Male:      [6]float64{100, 100, 100, 100, 100, 100},
+		Female:    [6]float64{100, 100, 100, 100, 100, 100},
+		Pregnancy: [3]float64{100, 100, 100}, Lactation: [3]float64{100, 100, 100}},
+
+	"Choline": {Unit: "mg",
+		Child:     [2]float64{1000, 1000},
+		Male:      [6]float64{2000, 3000, 3500, 3500, 3500, 3500},
+		Female:    [6]float64{2000, 3000, 3500, 3500, 3500, 3500},
+		Pregnancy: [3]float64{3000, 3500, 3500}, Lactation: [3]float64{3000, 3500, 3500}},
+}
+
+/*=================================================================================================*/
+
+// Limits returns the UL in mg for every nutrient that has one; the profile must be resolved
+func (p Profile) Limits() map[string]float64 {
+	limits := make(map[string]float64, len(ulTable))
+	for name, row := range ulTable {
+		limit := row.pick(p)
+		if limit <= 0 {
+			continue
+		}
+		if mg, err := units.ToMilligrams(name, units.Quantity{Value: limit, Unit: row.Unit}); err == nil {
+			limits[name] = mg
+		}
+	}
+	return limits
+}
+
+// ClassifyExcess maps a percentage of the UL onto a severity; false below 80%
+func ClassifyExcess(upperLimitPercent float64) (Severity, bool) {
+	switch {
+	case upperLimitPercent >= 200:
+		return Severe, true
+	case upperLimitPercent >= 100:
+		return Exceeded, true
+	case upperLimitPercent >= 80:
+		return Approaching, true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,250 @@
+// The-Nutrimancers-Codex/amplify/backend/server.go
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/accounts"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/analysis"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/api"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/cache"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/config"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/journal"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/metrics"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/models"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/services"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/utils"
+	"github.com/rs/cors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Request timeouts; the batch and stream handlers extend the write deadline for themselves
+const (
+	readHeaderTimeout = 5 * time.Second
+	readTimeout       = 30 * time.Second
+	writeTimeout      = 60 * time.Second // one analysis: a Gemini call plus a lookup per ingredient
+	idleTimeout       = 120 * time.Second
+	longWriteTimeout  = 10 * time.Minute // batches and streams
+)
+
+/*=================================================================================================*/
+
+// Server holds everything the handlers use. NewServer loads it from the config;
+// tests can build one directly with just the fields a handler needs.
+type Server struct {
+	config   config.Config
+	analyzer *analysis.Analyzer
+	accounts *accounts.Store
+	journal  *journal.Store
+	db       *bolt.DB
+}
+
+func NewServer(cfg config.Config) (*Server, error) {
+	// One client and connection pool for every upstream, counted per upstream in /metrics
+	client := services.NewHTTPClient(metrics.Transport(services.NewTransport(), services.Upstream))
+
+	s := &Server{config: cfg}
+
+	var err error
+
+	// Local store - accounts, meal journal & cached lookups
+	s.db, err = bolt.Open(cfg.Data.DatabasePath, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("opening local store: %v", err)
+	}
+	if s.accounts, err = accounts.New(s.db); err != nil {
+		s.db.Close()
+		return nil, fmt.Errorf("opening accounts: %v", err)
+	}
+	if s.journal, err = journal.New(s.db); err != nil {
+		s.db.Close()
+		return nil, fmt.Errorf("opening journal: %v", err)
+	}
+	// Thresholds, dataset & nutrient lookups - the cache can persist to the store
+	var wrap func(services.NutrientProvider) (services.NutrientProvider, error)
+	if cfg.Cache.Size > 0 {
+		cacheOptions := cache.Options{
+			Size:        cfg.Cache.Size,
+			TTL:         time.Duration(cfg.Cache.TTL),
+			NotFoundTTL: time.Duration(cfg.Cache.NotFoundTTL),
+			Observe:     metrics.ObserveCache,
+		}
+		if cfg.Cache.Persist {
+			cacheOptions.DB = s.db
+		}
+		wrap = func(next services.NutrientProvider) (services.NutrientProvider, error) {
+			return cache.New(next, cacheOptions)
+		}
+	}
+	if s.analyzer, err = analysis.New(cfg, client, wrap); err != nil {
+		s.db.Close()
+		return nil, err
+	}
+	s.analyzer.ObserveRecommender = metrics.ObserveRecommender
+	return s, nil
+}
+
+// Close releases the local store
+func (s *Server) Close() error {
+	if s.db == nil {
+		return nil
+	}
+	return s.db.Close()
+}
+
+// Handler is every route behind CORS - /v1 plus the unversioned originals, see api.Endpoints -
+// and the Prometheus /metrics
+func (s *Server) Handler() (http.Handler, error) {
+	routes, err := api.NewHandler(map[string]http.HandlerFunc{
+		"analyzeMeal":       s.processFoodHandler,
+		"analyzeMealBatch":  s.processFoodBatchHandler,
+		"analyzeMealStream": s.processFoodStreamHandler,
+		"lookupFood":        s.fetchNutrientDataHandler,
+
+		"register": s.registerHandler,
+		"login":    s.loginHandler,
+		"logout":   s.logoutHandler,
+
+		"createJournalEntry": s.createJournalEntryHandler,
+		"listJournalEntries": s.listJournalEntriesHandler,
+		"getJournalEntry":    s.getJournalEntryHandler,
+		"updateJournalEntry": s.updateJournalEntryHandler,
+		"deleteJournalEntry": s.deleteJournalEntryHandler,
+		"getJournalDay":      s.getJournalDayHandler,
+		"getJournalTrends":   s.getJournalTrendsHandler,
+
+		"health":    s.healthHandler,
+		"ready":     s.readyHandler,
+		"getConfig": s.getConfigHandler,
+	}, api.Guards{RequireUser: s.accounts.RequireUser, RequireAdmin: s.requireAdmin})
+	if err != nil {
+		return nil, err
+	}
+
+	c := cors.New(cors.Options{
+		AllowedOrigins:   s.config.Server.AllowedOrigins,
+		AllowedMethods:   []string{"POST", "GET", "OPTIONS", "PUT", "DELETE"},
+		AllowedHeaders:   []string{"Accept", "Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization"},
+		AllowCredentials: false, // bearer tokens are sent explicitly, not as cookies
+	})
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metrics.Handler())
+	mux.Handle("/", metrics.Instrument(routes))
+	return c.Handler(mux), nil
+}
+
+// ListenAndServe serves until ctx is cancelled, then stops accepting connections and
+// gives in-flight requests up to ShutdownTimeout to finish
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	handler, err := s.Handler()
+	if err != nil {
+		return err
+	}
+	httpServer := &http.Server{
+		Addr:              s.config.Server.Addr,
+		Handler:           handler,
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      writeTimeout,
+		IdleTimeout:       idleTimeout,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Println("Server is running on", s.config.Server.Addr)
+		serveErr <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Println("Shutting down, draining in-flight requests")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(s.config.Server.ShutdownTimeout))
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutdown: %v", err)
+	}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Batches and streams outlive writeTimeout
+func extendWriteDeadline(w http.ResponseWriter) {
+	http.NewResponseController(w).SetWriteDeadline(time.Now().Add(longWriteTimeout))
+}
+
+/*=================================================================================================*/
+
+// Admin endpoints take the configured admin token; without one they're switched off
+func (s *Server) requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.config.Admin.Token == "" {
+			utils.RespondWithError(w, http.StatusForbidden, "Admin endpoints are disabled: no admin token configured")
+			return
+		}
+		token, ok := accounts.BearerToken(r)
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.config.Admin.Token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="nutrimancer-admin"`)
+			utils.RespondWithError(w, http.StatusUnauthorized, "Admin token required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) getConfigHandler(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, s.config.Redacted())
+}
+
+/*=================================================================================================*/
+
+func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, models.HealthStatus{Status: "ok"})
+}
+
+// Ready once the dataset and store are loaded and Gemini and the nutrient provider are usable
+func (s *Server) readyHandler(w http.ResponseWriter, r *http.Request) {
+	checks := map[string]string{
+		"dataset":  "ok",
+		"store":    "ok",
+		"gemini":   "ok",
+		"provider": "ok",
+	}
+	if len(s.analyzer.FoodItems) == 0 {
+		checks["dataset"] = "no foods loaded from " + s.config.Data.DatasetPath
+	}
+	if s.db == nil {
+		checks["store"] = "not open"
+	} else if err := s.db.View(func(*bolt.Tx) error { return nil }); err != nil {
+		checks["store"] = err.Error()
+	}
+	if err := s.analyzer.Parser.Ready(); err != nil {
+		checks["gemini"] = err.Error()
+	}
+	if provider := s.analyzer.Provider; provider == nil {
+		checks["provider"] = "none configured"
+	} else if err := provider.Ready(); err != nil {
+		checks["provider"] = provider.Name() + ": " + err.Error()
+	}
+
+	status := models.HealthStatus{Status: "ready", Checks: checks}
+	code := http.StatusOK
+	for _, check := range checks {
+		if check != "ok" {
+			status.Status = "not ready"
+			code = http.StatusServiceUnavailable
+		}
+	}
+	respondWithJSON(w, code, status)
+}
@@ -0,0 +1,299 @@
+// The-Nutrimancers-Codex/amplify/backend/services/datasetService.go
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/models"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/nutrients"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/units"
+)
+
+// Dataset answers lookups offline from the bundled USDA dataset (machinist/dataset.csv).
+// Rows sharing a description are merged, since most of them are partial lab samples.
+type Dataset struct {
+	MinConfidence float64 // best match below this is ErrFoodNotFound
+	foods         []datasetFood
+	index         map[string][]int // token -> foods whose description has it
+}
+
+type datasetFood struct {
+	fdcID        string // most complete row
+	description  string
+	tokens       map[string]bool
+	head         []string           // first comma segment, what the food is ("rice" in "Rice, white, raw")
+	amounts      map[string]float64 // per 100 g, registry units; mean of the rows reporting it
+	completeness float64            // share of registry nutrients reported
+	raw          bool
+	sample       bool // lab sample or survey row ("Vitamin C, broccoli, raw (CA2,NC1) - NFY0904JO")
+}
+
+const DefaultDatasetMinConfidence = 0.35
+
+// Dataset columns are per 100 g in USDA units, which differ from the registry unit for these
+var datasetUnits = map[string]units.Unit{
+	"Vitamin D": units.Microgram, // USDA 328
+}
+
+// Regional and spelling variants, mapped onto the dataset's vocabulary
+var datasetSynonyms = map[string][]string{
+	"courgette": {"zucchini"},
+	"aubergine": {"eggplant"},
+	"prawn":     {"shrimp"},
+	"chickpea":  {"garbanzo"},
+	"garbanzo":  {"chickpea"},
+	"yoghurt":   {"yogurt"},
+	"mince":     {"ground"},
+	"minced":    {"ground"},
+	"pasta":     {"spaghetti", "macaroni"},
+	"capsicum":  {"pepper"},
+	"scallion":  {"onion"},
+	"oatmeal":   {"oat"},
+}
+
+// Words that describe the description, not the food
+var datasetStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "or": true, "of": true, "the": true, "with": true, "in": true,
+	"fresh": true, "chopped": true, "sliced": true, "diced": true, "large": true, "medium": true, "small": true,
+	"piece": true, "slice": true, "serving": true, "nfs": true, "ns": true,
+}
+
+// Tokens that mark sample and survey rows
+var datasetSampleTokens = map[string]bool{"nfy": true, "region": true, "pass": true, "composite": true, "minerals": true}
+
+// NewDataset indexes the loaded dataset rows
+func NewDataset(foodItems []models.FoodItem) *Dataset {
+	registered := nutrients.All()
+	type group struct {
+		food   datasetFood
+		sums   map[string]float64
+		counts map[string]int
+		best   int // reported nutrients in fdcID's row
+	}
+	groups := make(map[string]*group)
+	var order []string
+
+	for _, item := range foodItems {
+		tokens := datasetTokens(item.Description)
+		if len(tokens) == 0 {
+			continue
+		}
+		key := strings.Join(tokens, " ")
+		g, ok := groups[key]
+		if !ok {
+			g = &group{
+				food: datasetFood{
+					description: strings.TrimSpace(item.Description),
+					tokens:      make(map[string]bool, len(tokens)),
+					head:        datasetTokens(strings.SplitN(item.Description, ",", 2)[0]),
+				},
+				sums:   make(map[string]float64),
+				counts: make(map[string]int),
+				best:   -1,
+			}
+			for _, token := range tokens {
+				g.food.tokens[token] = true
+				if token == "raw" {
+					g.food.raw = true
+				}
+				if datasetSampleTokens[token] {
+					g.food.sample = true
+				}
+			}
+			// Lab rows lead with the analyte, e.g. "Selenium, chicken breasts, ..."
+			head, _, _ := strings.Cut(item.Description, ",")
+			for _, nutrient := range registered {
+				if strings.EqualFold(strings.TrimSpace(head), nutrient.Name) {
+					g.food.sample = true
+				}
+			}
+			groups[key] = g
+			order = append(order, key)
+		}
+
+		reported := 0
+		for name, value := range item.Nutrients {
+			if value > 0 {
+				g.sums[name] += value
+				g.counts[name]++
+				reported++
+			}
+		}
+		if reported > g.best {
+			g.food.fdcID, g.best = item.FdcID, reported
+		}
+	}
+
+	dataset := &Dataset{MinConfidence: DefaultDatasetMinConfidence, index: make(map[string][]int)}
+	for _, key := range order {
+		g := groups[key]
+		g.food.amounts = make(map[string]float64, len(g.counts))
+		for name, count := range g.counts {
+			amount := g.sums[name] / float64(count)
+			if from, ok := datasetUnits[name]; ok {
+				nutrient, _ := nutrients.Lookup(name)
+				converted, err := units.Convert(name, units.Quantity{Value: amount, Unit: from}, nutrient.Unit)
+				if err != nil {
+					continue
+				}
+				amount = converted.Value
+			}
+			g.food.amounts[name] = amount
+		}
+		g.food.completeness = float64(len(g.food.amounts)) / float64(len(registered))
+
+		i := len(dataset.foods)
+		dataset.foods = append(dataset.foods, g.food)
+		for token := range g.food.tokens {
+			dataset.index[token] = append(dataset.index[token], i)
+		}
+	}
+	return dataset
+}
+
+func (d *Dataset) Name() string {
+	return "offline"
+}
+
+func (d *Dataset) Ready() error {
+	if d == nil || len(d.foods) == 0 {
+		return errors.New("no dataset foods loaded")
+	}
+	return nil
+}
+
+// Lookup fuzzy-matches the ingredient against dataset descriptions and scales the match's
+// per-100 g amounts to the ingredient's portion
+func (d *Dataset) Lookup(ctx context.Context, ingredient models.Ingredient) (FoodNutrients, error) {
+	if err := d.Ready(); err != nil {
+		return FoodNutrients{}, err
+	}
+	if err := ctx.Err(); err != nil {
+		return FoodNutrients{}, err
+	}
+	food, confidence, ok := d.match(ingredient.Food)
+	if !ok || confidence < d.MinConfidence {
+		return FoodNutrients{}, ErrFoodNotFound
+	}
+
+	portion := ingredientPortion(ingredient)
+	amounts := make(map[string]float64, len(food.amounts))
+	for name, per100g := range food.amounts {
+		amounts[name] = per100g * portion.Grams / 100
+	}
+	return FoodNutrients{
+		Amounts: amounts,
+		Portion: portion,
+		Source: models.FoodSource{
+			Provider:    d.Name(),
+			ID:          food.fdcID,
+			Description: food.description,
+			Confidence:  confidence,
+		},
+	}, nil
+}
+
+// match scores every description sharing a token with the query. Confidence is how much of the
+// query the description covers and how little else it says; ranking also prefers the food
+// itself over dishes made with it, raw over prepared, and complete rows over lab samples.
+func (d *Dataset) match(food string) (datasetFood, float64, bool) {
+	query := datasetTokens(food)
+	if len(query) == 0 {
+		return datasetFood{}, 0, false
+	}
+	alternatives := make([][]string, len(query))
+	candidates := make(map[int]bool)
+	for i, token := range query {
+		alternatives[i] = append([]string{token}, datasetSynonyms[token]...)
+		for _, alternative := range alternatives[i] {
+			for _, candidate := range d.index[alternative] {
+				candidates[candidate] = true
+			}
+		}
+	}
+
+	type scored struct {
+		index             int
+		confidence, score float64
+	}
+	var results []scored
+	for candidate := range candidates {
+		f := d.foods[candidate]
+		matched, named := 0, make(map[string]bool)
+		for _, options := range alternatives {
+			for _, option := range options {
+				if f.tokens[option] {
+					matched++
+					named[option] = true
+					break
+				}
+			}
+		}
+		head := len(f.head) > 0
+		for _, token := range f.head {
+			head = head && named[token]
+		}
+		coverage := float64(matched) / float64(len(query))
+		precision := float64(matched) / float64(len(f.tokens))
+		confidence := coverage * (0.6 + 0.4*precision)
+
+		score := confidence + 0.2*f.completeness
+		if head {
+			score += 0.15
+		}
+		if f.raw {
+			score += 0.05
+		}
+		if f.sample {
+			score -= 0.5
+		}
+		results = append(results, scored{candidate, confidence, score})
+	}
+	if len(results) == 0 {
+		return datasetFood{}, 0, false
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].score != results[j].score {
+			return results[i].score > results[j].score
+		}
+		return results[i].index < results[j].index
+	})
+	best := results[0]
+	return d.foods[best.index], best.confidence, true
+}
+
+// datasetTokens lowercases, splits on anything but letters, drops stop words and singularizes
+func datasetTokens(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) })
+	tokens := make([]string, 0, len(fields))
+	seen := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		if len(field) < 2 || datasetStopWords[field] {
+			continue
+		}
+		token := singular(field)
+		if !seen[token] {
+			seen[token] = true
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+func singular(word string) string {
+	switch {
+	case len(word) <= 3 || strings.HasSuffix(word, "ss"):
+		return word
+	case strings.HasSuffix(word, "ies"):
+		return strings.TrimSuffix(word, "ies") + "y"
+	case strings.HasSuffix(word, "oes"), strings.HasSuffix(word, "ches"), strings.HasSuffix(word, "shes"), strings.HasSuffix(word, "xes"):
+		return strings.TrimSuffix(word, "es")
+	case strings.HasSuffix(word, "s"):
+		return strings.TrimSuffix(word, "s")
+	}
+	return word
+}
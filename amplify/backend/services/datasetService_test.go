@@ -0,0 +1,166 @@
+// The-Nutrimancers-Codex/amplify/backend/services/datasetService_test.go
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/models"
+)
+
+// A few dataset rows, per 100 g, shaped like machinist/dataset.csv's
+var datasetFixture = []models.FoodItem{
+	{FdcID: "1", Description: "Zucchini, raw", Nutrients: map[string]float64{"Potassium": 261, "Vitamin C": 17.9}},
+	{FdcID: "2", Description: "Squash, summer, zucchini, includes skin, cooked, boiled", Nutrients: map[string]float64{"Potassium": 264, "Vitamin C": 12.9}},
+	{FdcID: "3", Description: "Tomatoes, red, ripe, raw", Nutrients: map[string]float64{"Potassium": 237, "Vitamin C": 13.7}},
+	{FdcID: "4", Description: "Tomato soup, canned, condensed", Nutrients: map[string]float64{"Potassium": 282, "Sodium": 471}},
+	{FdcID: "5", Description: "Strawberries, raw", Nutrients: map[string]float64{"Vitamin C": 58.8}},
+	{FdcID: "6", Description: "Blueberries, raw", Nutrients: map[string]float64{"Vitamin C": 9.7}},
+	{FdcID: "7", Description: "Chicken, broilers or fryers, breast, meat only, raw", Nutrients: map[string]float64{"Selenium": 22.8, "Vitamin B3": 11.8}},
+	{FdcID: "8", Description: "Selenium, chicken breast, raw (NC1)", Nutrients: map[string]float64{"Selenium": 24.1}},
+	// Matches "turkey breast" more closely than the deli row does, and is raw: only the sample penalty ranks it below
+	{FdcID: "13", Description: "Selenium, turkey breast, raw", Nutrients: map[string]float64{"Selenium": 25.2}},
+	{FdcID: "14", Description: "Deli turkey breast, smoked, prepackaged", Nutrients: map[string]float64{"Selenium": 20.6, "Sodium": 1015}},
+	// Two rows of one food: merged, amounts averaged over the rows reporting each nutrient
+	{FdcID: "9", Description: "Spinach, raw", Nutrients: map[string]float64{"Iron": 2.6}},
+	{FdcID: "10", Description: "Spinach, raw", Nutrients: map[string]float64{"Iron": 3.0, "Vitamin K": 483, "Vitamin D": 0}},
+	{FdcID: "11", Description: "Spinach, cooked, boiled, drained, without salt", Nutrients: map[string]float64{"Iron": 3.57}},
+	{FdcID: "12", Description: "Fish oil, cod liver", Nutrients: map[string]float64{"Vitamin D": 250}},
+}
+
+func lookupDataset(t *testing.T, food string) (FoodNutrients, error) {
+	t.Helper()
+	dataset := NewDataset(datasetFixture)
+	return dataset.Lookup(context.Background(), models.Ingredient{Food: food, Quantity: 100, Unit: "g"})
+}
+
+/*=================================================================================================*/
+
+func TestDatasetMatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string // matched description
+	}{
+		{"synonym", "courgette", "Zucchini, raw"},
+		{"synonym over the dish it's in", "grilled courgette", "Zucchini, raw"},
+		{"plural -oes", "tomatoes", "Tomatoes, red, ripe, raw"},
+		{"singular query; the food itself over a dish with it", "tomato", "Tomatoes, red, ripe, raw"},
+		{"plural -ies", "strawberries", "Strawberries, raw"},
+		{"raw preferred over cooked", "spinach", "Spinach, raw"},
+		{"cooked when asked for", "boiled spinach", "Spinach, cooked, boiled, drained, without salt"},
+		{"lab sample ranked below the real food", "chicken breast", "Chicken, broilers or fryers, breast, meat only, raw"},
+		{"lab sample ranked below a prepared food", "turkey breast", "Deli turkey breast, smoked, prepackaged"},
+		{"stop words and sizes ignored", "2 large fresh blueberries", "Blueberries, raw"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := lookupDataset(t, test.query)
+			if err != nil {
+				t.Fatalf("Lookup(%q): %v", test.query, err)
+			}
+			if result.Source.Description != test.want {
+				t.Errorf("Lookup(%q) matched %q, want %q", test.query, result.Source.Description, test.want)
+			}
+			if result.Source.Provider != "offline" || result.Source.Confidence < DefaultDatasetMinConfidence || result.Source.Confidence > 1 {
+				t.Errorf("source = %+v, want offline with a confidence in [%g, 1]", result.Source, DefaultDatasetMinConfidence)
+			}
+		})
+	}
+}
+
+func TestDatasetNotFound(t *testing.T) {
+	for _, query := range []string{
+		"quinoa", // shares no token
+		"pork soup dumplings with ginger and chives", // shares only "soup": well below MinConfidence
+		"and the of", // nothing but stop words
+	} {
+		if result, err := lookupDataset(t, query); !errors.Is(err, ErrFoodNotFound) {
+			t.Errorf("Lookup(%q) = %q, %v; want ErrFoodNotFound", query, result.Source.Description, err)
+		}
+	}
+
+	// A lower threshold accepts the weak match
+	dataset := NewDataset(datasetFixture)
+	dataset.MinConfidence = 0
+	if _, err := dataset.Lookup(context.Background(), models.Ingredient{Food: "pork soup dumplings with ginger and chives"}); err != nil {
+		t.Errorf("Lookup with MinConfidence 0 = %v, want the weak match", err)
+	}
+}
+
+func TestDatasetMergedRows(t *testing.T) {
+	result, err := lookupDataset(t, "spinach")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Source.ID != "10" {
+		t.Errorf("fdc_id = %s, want 10, the row reporting the most nutrients", result.Source.ID)
+	}
+	want := map[string]float64{
+		"Iron":      2.8, // mean of 2.6 and 3.0
+		"Vitamin K": 483, // only one row reports it: not halved
+	}
+	if len(result.Amounts) != len(want) {
+		t.Errorf("amounts = %v, want %v (a zero isn't a report)", result.Amounts, want)
+	}
+	for name, amount := range want {
+		if got := result.Amounts[name]; math.Abs(got-amount) > 1e-9 {
+			t.Errorf("%s = %g, want %g", name, got, amount)
+		}
+	}
+}
+
+func TestDatasetScalesAndConverts(t *testing.T) {
+	dataset := NewDataset(datasetFixture)
+	result, err := dataset.Lookup(context.Background(), models.Ingredient{Food: "cod liver oil", Quantity: 5, Unit: "g"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 250 µg per 100 g, in the registry's IU (40 IU/µg), for 5 g
+	if got := result.Amounts["Vitamin D"]; math.Abs(got-500) > 1e-9 {
+		t.Errorf("vitamin D = %g IU, want 500", got)
+	}
+	if result.Portion != (models.Portion{Quantity: 5, Unit: "g", Grams: 5}) {
+		t.Errorf("portion = %+v, want the stated 5 g", result.Portion)
+	}
+}
+
+func TestDatasetTokens(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Tomatoes, red, ripe, raw", []string{"tomato", "red", "ripe", "raw"}},
+		{"2 Large Eggs, chopped", []string{"egg"}},
+		{"Selenium, chicken breast, raw (NC1)", []string{"selenium", "chicken", "breast", "raw", "nc"}},
+		{"beans and more beans", []string{"bean", "more"}},
+	}
+	for _, test := range tests {
+		if got := datasetTokens(test.text); !slices.Equal(got, test.want) {
+			t.Errorf("datasetTokens(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
+
+func TestSingular(t *testing.T) {
+	tests := map[string]string{
+		"tomatoes": "tomato",
+		"berries":  "berry",
+		"peaches":  "peach",
+		"radishes": "radish",
+		"boxes":    "box",
+		"eggs":     "egg",
+		"oats":     "oat",
+		"glass":    "glass", // -ss isn't a plural
+		"gas":      "gas",   // too short to tell
+		"rice":     "rice",
+	}
+	for word, want := range tests {
+		if got := singular(word); got != want {
+			t.Errorf("singular(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
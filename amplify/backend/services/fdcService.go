@@ -0,0 +1,237 @@
+// The-Nutrimancers-Codex/amplify/backend/services/fdcService.go
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/models"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/nutrients"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/units"
+)
+
+type FDCSearchRequest struct {
+	Query    string   `json:"query"`
+	DataType []string `json:"dataType,omitempty"`
+	PageSize int      `json:"pageSize"`
+}
+type FDCSearchResponse struct {
+	Foods []struct {
+		FdcID       int    `json:"fdcId"`
+		Description string `json:"description"`
+	} `json:"foods"`
+}
+type FDCFood struct {
+	FdcID         int    `json:"fdcId"`
+	Description   string `json:"description"`
+	FoodNutrients []struct {
+		Nutrient struct {
+			Number   string `json:"number"`
+			UnitName string `json:"unitName"`
+		} `json:"nutrient"`
+		Amount float64 `json:"amount"` // per 100 g
+	} `json:"foodNutrients"`
+	FoodPortions []FDCPortion `json:"foodPortions"`
+}
+type FDCPortion struct {
+	Amount      float64 `json:"amount"`
+	GramWeight  float64 `json:"gramWeight"`
+	Modifier    string  `json:"modifier"` // SR Legacy puts the measure here: "cup, chopped", "large"
+	MeasureUnit struct {
+		Name         string `json:"name"`
+		Abbreviation string `json:"abbreviation"`
+	} `json:"measureUnit"`
+}
+
+// FoodDataCentral is the USDA FoodData Central API: search for the food, then read its
+// nutrients (per 100 g, by USDA nutrient number) and household portions from the details
+type FoodDataCentral struct {
+	Endpoint  string       // API base, e.g. DefaultFDCEndpoint
+	APIKey    string       // api.data.gov key
+	DataTypes []string     // search only these, e.g. the Foundation and SR Legacy foods the dataset is built from
+	Client    *http.Client // nil uses a shared default
+}
+
+const DefaultFDCEndpoint = "https://api.nal.usda.gov/fdc/v1"
+
+var DefaultFDCDataTypes = []string{"Foundation", "SR Legacy"}
+
+func (f FoodDataCentral) Name() string {
+	return "fdc"
+}
+
+func (f FoodDataCentral) Ready() error {
+	if f.APIKey == "" {
+		return errors.New("missing FoodData Central API key")
+	}
+	return nil
+}
+
+// Lookup takes the best search hit's details and scales them to the ingredient's portion
+func (f FoodDataCentral) Lookup(ctx context.Context, ingredient models.Ingredient) (FoodNutrients, error) {
+	if err := f.Ready(); err != nil {
+		return FoodNutrients{}, err
+	}
+
+	var search FDCSearchResponse
+	request := FDCSearchRequest{Query: ingredient.Food, DataType: f.DataTypes, PageSize: 1}
+	if err := f.do(ctx, "POST", "/foods/search", request, &search); err != nil {
+		return FoodNutrients{}, err
+	}
+	if len(search.Foods) == 0 {
+		return FoodNutrients{}, ErrFoodNotFound
+	}
+
+	var food FDCFood
+	if err := f.do(ctx, "GET", "/food/"+strconv.Itoa(search.Foods[0].FdcID), nil, &food); err != nil {
+		return FoodNutrients{}, err
+	}
+
+	portion := fdcPortion(ingredient, food.FoodPortions)
+	amounts := make(map[string]float64)
+	for _, nutrient := range nutrients.All() {
+		for _, number := range append([]string{nutrient.USDANumber}, fdcAlternateNumbers[nutrient.USDANumber]...) {
+			amount, found, err := food.amount(nutrient, number)
+			if err != nil {
+				// One unfamiliar unit shouldn't cost the whole meal
+				log.Printf("fdc: %s in %d: %v; skipped", nutrient.Name, food.FdcID, err)
+				break
+			}
+			if found {
+				amounts[nutrient.Name] = amount * portion.Grams / 100
+				break
+			}
+		}
+	}
+	return FoodNutrients{
+		Amounts: amounts,
+		Portion: portion,
+		Source: models.FoodSource{
+			Provider:    f.Name(),
+			ID:          strconv.Itoa(food.FdcID),
+			Description: food.Description,
+		},
+	}, nil
+}
+
+// Numbers tried in order when a food doesn't report the registry's: Foundation foods give energy
+// as 957 (Atwater general factors) or 958 (Atwater specific factors) rather than 208
+var fdcAlternateNumbers = map[string][]string{
+	"208": {"957", "958"},
+}
+
+// amount is the food's per-100 g amount of the nutrient under number, in the registry unit
+func (food FDCFood) amount(nutrient nutrients.Nutrient, number string) (float64, bool, error) {
+	for _, fn := range food.FoodNutrients {
+		if fn.Nutrient.Number != number {
+			continue
+		}
+		unit, err := units.Parse(fn.Nutrient.UnitName)
+		if err != nil {
+			return 0, false, err
+		}
+		amount, err := units.Convert(nutrient.Name, units.Quantity{Value: fn.Amount, Unit: unit}, nutrient.Unit)
+		if err != nil {
+			return 0, false, err
+		}
+		return amount.Value, true, nil
+	}
+	return 0, false, nil
+}
+
+// do sends payload (if set) as JSON and decodes the response into out
+func (f FoodDataCentral) do(ctx context.Context, method, path string, payload interface{}, out interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		jsonData, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewBuffer(jsonData)
+	}
+	req, err := http.NewRequestWithContext(withUpstream(ctx, f.Name()), method, strings.TrimSuffix(f.Endpoint, "/")+path, body)
+	if err != nil {
+		return err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("X-Api-Key", f.APIKey) // header, not ?api_key=, so it stays out of logged URLs
+
+	resp, err := httpClient(f.Client).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := ioutil.ReadAll(resp.Body)
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return fmt.Errorf("fdc API error: %w: %s", ErrQuotaExceeded, string(bodyBytes))
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			return ErrFoodNotFound
+		}
+		return fmt.Errorf("fdc API error: %s", string(bodyBytes))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// fdcPortion is the ingredient in grams: mass and volume units directly, otherwise the food's
+// household portion with that measure ("slice", "cup", "large" for counted eggs)
+func fdcPortion(ingredient models.Ingredient, portions []FDCPortion) models.Portion {
+	if _, ok := gramsPerUnit[ingredient.Unit]; ok && ingredient.Quantity > 0 {
+		return ingredientPortion(ingredient)
+	}
+	for _, p := range portions {
+		if p.GramWeight <= 0 || !p.measures(ingredient.Unit) {
+			continue
+		}
+		quantity, unit := ingredient.Quantity, ingredient.Unit
+		if quantity <= 0 {
+			quantity = 1
+		}
+		if unit == "" {
+			unit = p.label()
+		}
+		amount := p.Amount
+		if amount <= 0 {
+			amount = 1
+		}
+		return models.Portion{Quantity: quantity, Unit: unit, Grams: quantity * p.GramWeight / amount, Assumed: ingredient.Quantity <= 0}
+	}
+	return ingredientPortion(ingredient)
+}
+
+// measures reports whether the portion is in unit; no unit means a counted item and takes the first portion
+func (p FDCPortion) measures(unit string) bool {
+	if unit == "" {
+		return true
+	}
+	modifier, _, _ := strings.Cut(p.Modifier, ",")
+	for _, name := range []string{p.MeasureUnit.Name, p.MeasureUnit.Abbreviation, strings.TrimSpace(modifier)} {
+		if name != "" && singular(strings.ToLower(name)) == singular(unit) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p FDCPortion) label() string {
+	if p.Modifier != "" {
+		return p.Modifier
+	}
+	if p.MeasureUnit.Name != "undetermined" {
+		return p.MeasureUnit.Name
+	}
+	return ""
+}
@@ -0,0 +1,230 @@
+// The-Nutrimancers-Codex/amplify/backend/services/fdcService_test.go
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/models"
+)
+
+// Egg, whole, raw (SR Legacy 171287), trimmed to a few nutrients, per 100 g
+const fdcEgg = `{
+	"fdcId": 171287,
+	"description": "Egg, whole, raw, fresh",
+	"foodNutrients": [
+		{"nutrient": {"number": "203", "unitName": "g"}, "amount": 12.6},
+		{"nutrient": {"number": "303", "unitName": "mg"}, "amount": 1.75},
+		{"nutrient": {"number": "317", "unitName": "UG"}, "amount": 30.7},
+		{"nutrient": {"number": "328", "unitName": "µg"}, "amount": 2},
+		{"nutrient": {"number": "999", "unitName": "mg"}, "amount": 5}
+	],
+	"foodPortions": [
+		{"amount": 1, "gramWeight": 50, "modifier": "large", "measureUnit": {"name": "undetermined"}},
+		{"amount": 1, "gramWeight": 243, "modifier": "", "measureUnit": {"name": "cup", "abbreviation": "cup"}}
+	]
+}`
+
+// fakeFDC serves /foods/search and /food/{id}; search and detail set the status code of each
+// (0 means 200), foods is what search finds and food the details (fdcEgg when empty)
+type fakeFDC struct {
+	t            *testing.T
+	search       int
+	detail       int
+	foods        string
+	food         string
+	lastSearch   FDCSearchRequest
+	detailCalled bool
+}
+
+func (f *fakeFDC) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Api-Key") != "test-key" {
+		f.t.Errorf("%s %s: X-Api-Key = %q, want the configured key", r.Method, r.URL.Path, r.Header.Get("X-Api-Key"))
+	}
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/foods/search":
+		if err := json.NewDecoder(r.Body).Decode(&f.lastSearch); err != nil {
+			f.t.Errorf("decoding search request: %v", err)
+		}
+		if f.search != 0 {
+			http.Error(w, `{"error": "search failed"}`, f.search)
+			return
+		}
+		w.Write([]byte(`{"foods": ` + f.foods + `}`))
+	case r.Method == http.MethodGet && r.URL.Path == "/food/171287":
+		f.detailCalled = true
+		if f.detail != 0 {
+			http.Error(w, `{"error": "detail failed"}`, f.detail)
+			return
+		}
+		if f.food == "" {
+			f.food = fdcEgg
+		}
+		w.Write([]byte(f.food))
+	default:
+		f.t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		http.NotFound(w, r)
+	}
+}
+
+func newFakeFDC(t *testing.T, fake *fakeFDC) FoodDataCentral {
+	fake.t = t
+	if fake.foods == "" {
+		fake.foods = `[{"fdcId": 171287, "description": "Egg, whole, raw, fresh"}]`
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return FoodDataCentral{Endpoint: server.URL + "/", APIKey: "test-key", DataTypes: DefaultFDCDataTypes, Client: server.Client()}
+}
+
+/*=================================================================================================*/
+
+func TestFDCLookup(t *testing.T) {
+	tests := []struct {
+		name       string
+		ingredient models.Ingredient
+		portion    models.Portion
+	}{
+		{"counted items use the first household portion", models.Ingredient{Food: "egg", Quantity: 2},
+			models.Portion{Quantity: 2, Unit: "large", Grams: 100}},
+		{"household measure", models.Ingredient{Food: "egg", Quantity: 3, Unit: "large"},
+			models.Portion{Quantity: 3, Unit: "large", Grams: 150}},
+		{"volumes at water density, not the food's cup", models.Ingredient{Food: "egg", Quantity: 0.5, Unit: "cup"},
+			models.Portion{Quantity: 0.5, Unit: "cup", Grams: 120}},
+		{"grams", models.Ingredient{Food: "egg", Quantity: 50, Unit: "g"},
+			models.Portion{Quantity: 50, Unit: "g", Grams: 50}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := &fakeFDC{}
+			fdc := newFakeFDC(t, fake)
+
+			result, err := fdc.Lookup(context.Background(), test.ingredient)
+			if err != nil {
+				t.Fatalf("Lookup: %v", err)
+			}
+			if fake.lastSearch.Query != "egg" || fake.lastSearch.PageSize != 1 || len(fake.lastSearch.DataType) != 2 {
+				t.Errorf("search request = %+v, want egg in Foundation and SR Legacy, one hit", fake.lastSearch)
+			}
+			if result.Portion != test.portion {
+				t.Errorf("portion = %+v, want %+v", result.Portion, test.portion)
+			}
+			if result.Source != (models.FoodSource{Provider: "fdc", ID: "171287", Description: "Egg, whole, raw, fresh"}) {
+				t.Errorf("source = %+v, want fdc 171287", result.Source)
+			}
+
+			// Per-100 g amounts scaled to the portion; "UG" and µg parsed, vitamin D µg -> IU
+			scale := test.portion.Grams / 100
+			want := map[string]float64{
+				"Protein":   12.6 * scale,
+				"Iron":      1.75 * scale,
+				"Selenium":  30.7 * scale,
+				"Vitamin D": 80 * scale,
+			}
+			if len(result.Amounts) != len(want) {
+				t.Errorf("amounts = %v, want only %v (unregistered numbers dropped)", result.Amounts, want)
+			}
+			for name, amount := range want {
+				if got, ok := result.Amounts[name]; !ok || math.Abs(got-amount) > 1e-9 {
+					t.Errorf("%s = %g (reported %t), want %g", name, got, ok, amount)
+				}
+			}
+		})
+	}
+}
+
+func TestFDCLookupNutrients(t *testing.T) {
+	food := func(nutrients string) string {
+		return `{"fdcId": 171287, "description": "Egg, whole, raw, fresh", "foodNutrients": [` + nutrients + `]}`
+	}
+	tests := []struct {
+		name string
+		food string
+		want map[string]float64 // per 100 g
+	}{
+		{"SR Legacy energy", food(`
+			{"nutrient": {"number": "208", "unitName": "KCAL"}, "amount": 143},
+			{"nutrient": {"number": "957", "unitName": "KCAL"}, "amount": 150}`),
+			map[string]float64{"Energy": 143}},
+		{"Foundation energy, general factors", food(`
+			{"nutrient": {"number": "958", "unitName": "KCAL"}, "amount": 148},
+			{"nutrient": {"number": "957", "unitName": "KCAL"}, "amount": 150}`),
+			map[string]float64{"Energy": 150}},
+		{"Foundation energy, specific factors only", food(`
+			{"nutrient": {"number": "958", "unitName": "KCAL"}, "amount": 148}`),
+			map[string]float64{"Energy": 148}},
+		{"energy in kJ", food(`
+			{"nutrient": {"number": "957", "unitName": "kJ"}, "amount": 627.6}`),
+			map[string]float64{"Energy": 150}},
+		{"unknown unit skips only that nutrient", food(`
+			{"nutrient": {"number": "303", "unitName": "furlongs"}, "amount": 1.75},
+			{"nutrient": {"number": "203", "unitName": "g"}, "amount": 12.6}`),
+			map[string]float64{"Protein": 12.6}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fdc := newFakeFDC(t, &fakeFDC{food: test.food})
+			result, err := fdc.Lookup(context.Background(), models.Ingredient{Food: "egg", Quantity: 100, Unit: "g"})
+			if err != nil {
+				t.Fatalf("Lookup: %v", err)
+			}
+			if len(result.Amounts) != len(test.want) {
+				t.Errorf("amounts = %v, want %v", result.Amounts, test.want)
+			}
+			for name, amount := range test.want {
+				if got, ok := result.Amounts[name]; !ok || math.Abs(got-amount) > 1e-6 {
+					t.Errorf("%s = %g (reported %t), want %g", name, got, ok, amount)
+				}
+			}
+		})
+	}
+}
+
+func TestFDCLookupErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		fake fakeFDC
+		want error
+	}{
+		{"no search results", fakeFDC{foods: `[]`}, ErrFoodNotFound},
+		{"food detail not found", fakeFDC{detail: http.StatusNotFound}, ErrFoodNotFound},
+		{"search rate limited", fakeFDC{search: http.StatusTooManyRequests}, ErrQuotaExceeded},
+		{"detail rate limited", fakeFDC{detail: http.StatusTooManyRequests}, ErrQuotaExceeded},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fdc := newFakeFDC(t, &test.fake)
+			result, err := fdc.Lookup(context.Background(), models.Ingredient{Food: "egg", Quantity: 1})
+			if !errors.Is(err, test.want) {
+				t.Errorf("Lookup = %+v, %v; want %v", result, err, test.want)
+			}
+		})
+	}
+
+	t.Run("empty results skip the detail request", func(t *testing.T) {
+		fake := &fakeFDC{foods: `[]`}
+		newFakeFDC(t, fake).Lookup(context.Background(), models.Ingredient{Food: "egg"})
+		if fake.detailCalled {
+			t.Error("fetched food details after an empty search")
+		}
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		fdc := newFakeFDC(t, &fakeFDC{search: http.StatusInternalServerError})
+		_, err := fdc.Lookup(context.Background(), models.Ingredient{Food: "egg"})
+		if err == nil || errors.Is(err, ErrFoodNotFound) || errors.Is(err, ErrQuotaExceeded) {
+			t.Errorf("Lookup error = %v, want a plain API error", err)
+		}
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		if _, err := (FoodDataCentral{Endpoint: "http://127.0.0.1:1"}).Lookup(context.Background(), models.Ingredient{Food: "egg"}); err == nil {
+			t.Error("Lookup without an API key succeeded")
+		}
+	})
+}
@@ -3,124 +3,163 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
-	"os"
 	"strings"
 
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/models"
 	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/utils"
 )
 
 /*=================================================================================================*/
 
-// Request payload structure for Gemini API
-type GeminiRequest struct {
-	Contents []Content `json:"contents"`
+// IngredientParser turns a food description into ingredients with their stated quantities
+type IngredientParser interface {
+	ExtractIngredients(ctx context.Context, foodDescription string) ([]models.Ingredient, error)
+	Ready() error // nil once the parser can answer, e.g. its key is set
 }
 
-type Content struct {
-	Parts []Part `json:"parts"`
+// Gemini is the ingredient-extraction model: API base URL, model name and key
+type Gemini struct {
+	Endpoint string
+	Model    string
+	APIKey   string
+	Client   *http.Client // nil uses a shared default
 }
 
-type Part struct {
-	Text string `json:"text"`
-}
-
-type GeminiChoice struct {
-	Text string `json:"text"`
-}
+const (
+	DefaultGeminiEndpoint = "https://generativelanguage.googleapis.com/v1beta"
+	DefaultGeminiModel    = "gemini-1.5-flash-latest"
+)
 
-type GeminiResponse struct {
-	Choices []GeminiChoice `json:"choices"`
+func (g Gemini) Ready() error {
+	if g.APIKey == "" {
+		return errors.New("API key missing")
+	}
+	return nil
 }
 
-/*=================================================================================================*/
-
 // Primary Prompt: Accepts user food description dynamically and sends to Gemini API
-func ExtractIngredients(foodDescription string) ([]string, error) {
-	apiKey := os.Getenv("API_KEY")
-	if apiKey == "" {
-		err := errors.New("API_KEY not set")
-		utils.LogError(err, "ExtractIngredients")
+func (g Gemini) ExtractIngredients(ctx context.Context, foodDescription string) ([]models.Ingredient, error) {
+	text, err := g.GenerateContent(ctx, IngredientPrompt(foodDescription))
+	if err != nil {
 		return nil, err
 	}
+	return ParseIngredients(text)
+}
 
-	// PROMPT
-	promptText := fmt.Sprintf("Extract the list of ingredients from the following food description: '%s'. List the main ingredients as bullet points with no descriptions.", foodDescription)
+// GenerateContent sends one prompt and returns the first candidate's text, asking for JSON output
+func (g Gemini) GenerateContent(ctx context.Context, prompt string) (string, error) {
+	if g.APIKey == "" {
+		err := errors.New("API_KEY not set")
+		utils.LogError(err, "GenerateContent")
+		return "", err
+	}
 
 	// Prep Request Body
-	reqBody := GeminiRequest{
-		Contents: []Content{{Parts: []Part{{
-			Text: promptText,
-		}}}},
+	reqBody := models.GeminiRequest{
+		Contents:         []models.Content{{Parts: []models.Part{{Text: prompt}}}},
+		GenerationConfig: &models.GenerationConfig{ResponseMimeType: "application/json"},
 	}
 
 	// Convert request body to JSON
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		utils.LogError(err, "ExtractIngredients: Marshal")
-		return nil, err
+		utils.LogError(err, "GenerateContent: Marshal")
+		return "", err
 	}
 
 	// Gemini API Request
-	endpoint := "https://generativelanguage.googleapis.com/v1beta/models/gemini-1.5-flash-latest:generateContent?key=" + apiKey
-	req, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(jsonData))
+	endpoint := strings.TrimSuffix(g.Endpoint, "/") + "/models/" + g.Model + ":generateContent"
+	req, err := http.NewRequestWithContext(withUpstream(ctx, "gemini"), "POST", endpoint, bytes.NewBuffer(jsonData))
 	if err != nil {
-		utils.LogError(err, "ExtractIngredients: NewRequest")
-		return nil, err
+		utils.LogError(err, "GenerateContent: NewRequest")
+		return "", err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-goog-api-key", g.APIKey) // a header keeps the key out of URLs in errors and logs
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := httpClient(g.Client).Do(req)
 	if err != nil {
-		utils.LogError(err, "ExtractIngredients: DoRequest")
-		return nil, err
+		utils.LogError(err, "GenerateContent: DoRequest")
+		return "", err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := ioutil.ReadAll(resp.Body)
 		errMsg := fmt.Sprintf("Gemini API error: %s", string(bodyBytes))
-		utils.LogError(errors.New(errMsg), "ExtractIngredients: API Error")
-		return nil, errors.New(errMsg)
+		utils.LogError(errors.New(errMsg), "GenerateContent: API Error")
+		return "", errors.New(errMsg)
 	}
 
 	// Parsing Response
-	var geminiResp GeminiResponse
+	var geminiResp models.GeminiResponse
 	if err := json.NewDecoder(resp.Body).Decode(&geminiResp); err != nil {
-		utils.LogError(err, "ExtractIngredients: Decode")
-		return nil, err
+		utils.LogError(err, "GenerateContent: Decode")
+		return "", err
 	}
 
-	// Optional choices later?
-	if len(geminiResp.Choices) == 0 {
-		errMsg := "no choices returned from gemini"
-		utils.LogError(errors.New(errMsg), "no gemini choices")
-		return nil, errors.New(errMsg)
+	if len(geminiResp.Candidates) == 0 || len(geminiResp.Candidates[0].Content.Parts) == 0 {
+		errMsg := "no candidates returned from gemini"
+		utils.LogError(errors.New(errMsg), "no gemini candidates")
+		return "", errors.New(errMsg)
 	}
-	text := geminiResp.Choices[0].Text
-	ingredients := parseIngredients(text)
-	return ingredients, nil
+	return geminiResp.Candidates[0].Content.Parts[0].Text, nil
 }
 
 /*=================================================================================================*/
 
-// Parse & Clean Ingredients
-func parseIngredients(text string) []string {
-	var ingredients []string
-	lines := strings.Split(text, "\n")
-	for _, line := range lines {
+// IngredientPrompt asks for structured {food, quantity, unit} items so portions survive extraction
+func IngredientPrompt(foodDescription string) string {
+	return fmt.Sprintf("Extract the essential ingredients from the following food description: '%s'. "+
+		"For complex foods like pizza, include the base components (e.g., dough, cheese, tomato sauce). Exclude spices and minor ingredients. "+
+		"Respond with a JSON array of objects with keys \"food\" (a short food name), \"quantity\" (a number, 0 if the description gives none) "+
+		"and \"unit\" (e.g. \"g\", \"cup\", \"slice\"; empty for counted items like eggs or when no quantity is given).", foodDescription)
+}
+
+// Parse & Clean Ingredients - the JSON array from IngredientPrompt, or bullet lines without quantities.
+// Text that looks like JSON but doesn't parse as the array is an error, not a bullet list.
+func ParseIngredients(text string) ([]models.Ingredient, error) {
+	trimmed := strings.TrimSpace(text)
+	trimmed = strings.TrimPrefix(trimmed, "```json")
+	trimmed = strings.Trim(trimmed, "`\n ")
+
+	if strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "{") {
+		var items []models.Ingredient
+		if err := json.Unmarshal([]byte(trimmed), &items); err != nil {
+			return nil, fmt.Errorf("parsing gemini ingredients: %w", err)
+		}
+		var ingredients []models.Ingredient
+		for _, item := range items {
+			item.Food = strings.ToLower(strings.TrimSpace(item.Food))
+			item.Unit = strings.ToLower(strings.TrimSpace(item.Unit))
+			if item.Food == "" {
+				continue
+			}
+			if item.Quantity < 0 {
+				item.Quantity = 0
+			}
+			ingredients = append(ingredients, item)
+		}
+		return ingredients, nil
+	}
+
+	var ingredients []models.Ingredient
+	for _, line := range strings.Split(text, "\n") {
 		cleaned := strings.TrimSpace(line)
-		cleaned = strings.Trim(cleaned, "-•,.")
+		cleaned = strings.ReplaceAll(cleaned, "*", "")
+		cleaned = strings.Trim(cleaned, "-•,. ")
 		cleaned = strings.ToLower(cleaned)
-		if len(cleaned) > 0 {
-			ingredients = append(ingredients, cleaned)
+		// "Ingredients:"-style headings and prose aren't ingredients
+		if len(cleaned) > 0 && !strings.HasSuffix(cleaned, ":") && len(cleaned) < 50 {
+			ingredients = append(ingredients, models.Ingredient{Food: cleaned})
 		}
 	}
-	return ingredients
+	return ingredients, nil
 }
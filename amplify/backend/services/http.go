@@ -0,0 +1,55 @@
+// The-Nutrimancers-Codex/amplify/backend/services/http.go
+package services
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+)
+
+// NewTransport is the connection pool every upstream shares, sized for a few hosts called in bursts
+func NewTransport() *http.Transport {
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           (&net.Dialer{Timeout: 5 * time.Second, KeepAlive: 30 * time.Second}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          64,
+		MaxIdleConnsPerHost:   16, // a meal's concurrent lookups reuse warm connections
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: 30 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+// NewHTTPClient is the client Gemini and the providers share. Timeout bounds one call;
+// the request's context cancels it sooner when the caller goes away.
+func NewHTTPClient(transport http.RoundTripper) *http.Client {
+	return &http.Client{Transport: transport, Timeout: 60 * time.Second}
+}
+
+// For zero-value Client fields
+var defaultClient = NewHTTPClient(NewTransport())
+
+func httpClient(client *http.Client) *http.Client {
+	if client == nil {
+		return defaultClient
+	}
+	return client
+}
+
+type upstreamKey struct{}
+
+// withUpstream tags a request context with the upstream it's for, see Upstream
+func withUpstream(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, upstreamKey{}, name)
+}
+
+// Upstream names the API a request is for ("gemini", "nutritionix", "fdc"), e.g. for metrics labels
+func Upstream(req *http.Request) string {
+	if name, ok := req.Context().Value(upstreamKey{}).(string); ok {
+		return name
+	}
+	return "unknown"
+}
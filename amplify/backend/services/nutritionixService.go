@@ -3,12 +3,15 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
-	"os"
+
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/models"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/nutrients"
 )
 
 type NutritionixRequest struct {
@@ -19,141 +22,126 @@ type FullNutrient struct {
 	Value  float64 `json:"value"`
 }
 type NutritionixFood struct {
-	FoodName      string         `json:"food_name"`
-	ServingQty    float64        `json:"serving_qty"`
-	ServingUnit   string         `json:"serving_unit"`
-	FullNutrients []FullNutrient `json:"full_nutrients"`
+	FoodName           string         `json:"food_name"`
+	ServingQty         float64        `json:"serving_qty"`
+	ServingUnit        string         `json:"serving_unit"`
+	ServingWeightGrams float64        `json:"serving_weight_grams"`
+	FullNutrients      []FullNutrient `json:"full_nutrients"`
 }
 type NutritionixResponse struct {
 	Foods []NutritionixFood `json:"foods"`
 }
 
-/*=================================================================================================*/
-// Mapping of WIKI essential nutrients to Nutritionix attr_ids
-var nutrientMapping = map[string]int{
-	"Potassium":  306, // Rest mg
-	"Sodium":     307,
-	"Calcium":    301,
-	"Phosphorus": 305,
-	"Magnesium":  304,
-	"Iron":       303,
-	"Zinc":       309,
-	"Manganese":  315,
-	"Copper":     312,
-	"Selenium":   317, //Âµg
-	// "Iodine":
-	// "Chromium":
-	// "Molybdenum": TRACE
-	"Histidine":     512, // All grams
-	"Isoleucine":    503,
-	"Leucine":       504,
-	"Lysine":        505,
-	"Methionine":    506,
-	"Phenylalanine": 508,
-	"Threonine":     502, // X
-	"Tryptophan":    501, // X
-	"Valine":        510,
-
-	"Alpha-Linolenic Acid": 851, // g Omega3
-	"Linoleic Acid":        675, // g Omega6
-	"EPA":                  629, // g (Omega-3)
-	"DHA":                  621, // g (Omega-3)
-
-	"Vitamin A":  320, // Âµg RAE instead of IU
-	"Vitamin B1": 404, // Thiamin mg
-	"Vitamin B2": 405, // Riboflavin mg
-	"Vitamin B3": 406, // Niacin mg
-	"Vitamin B5": 410, // Pantothenic acid mg
-	"Vitamin B6": 415, // mg
-	// "Vitamin B7":	(Biotin)
-	"Vitamin B9":  417, // Âµg Folate
-	"Vitamin B12": 418, // Âµg
-	"Vitamin C":   401, // mg
-	"Vitamin D":   324, // IU****
-	"Vitamin E":   323, // alpha-tocopherol mg
-	"Vitamin K":   430, // Âµg
-	"Choline":     421, // mg
+// Nutritionix is the natural-language nutrients endpoint and its app credentials
+type Nutritionix struct {
+	Endpoint string
+	AppID    string
+	AppKey   string
+	Client   *http.Client // nil uses a shared default
 }
 
-/*=================================================================================================*/
+const DefaultNutritionixEndpoint = "https://trackapi.nutritionix.com/v2/natural/nutrients"
 
-// Fetch nutrient data for each individual ingredient from Nutritionix API
-func FetchNutrientDataForEachIngredient(ingredients []string) (map[string]map[string]float64, error) {
-	nutrientsPerIngredient := make(map[string]map[string]float64)
+func (n Nutritionix) Name() string {
+	return "nutritionix"
+}
 
-	for _, ingredient := range ingredients {
-		nutrientData, err := FetchNutrientData([]string{ingredient}) // one ingredient at a time
-		if err != nil {
-			return nil, fmt.Errorf("error fetching nutrient data for %s: %v", ingredient, err)
-		}
-		fmt.Printf("Nutrient data for %s: %+v\n", ingredient, nutrientData)
-		nutrientsPerIngredient[ingredient] = nutrientData[ingredient]
+func (n Nutritionix) Ready() error {
+	if n.AppID == "" || n.AppKey == "" {
+		return errors.New("missing Nutritionix API credentials")
 	}
-	return nutrientsPerIngredient, nil
+	return nil
 }
 
-func FetchNutrientData(ingredients []string) (map[string]map[string]float64, error) {
-	appID := os.Getenv("NUTRITIONIX_APP_ID") // Load from .env
-	appKey := os.Getenv("NUTRITIONIX_APP_KEY")
-	if appID == "" || appKey == "" {
-		return nil, errors.New("missing Nutritionix API credentials in .env")
+// Lookup queries Nutritionix with the ingredient's quantity so amounts are for that portion
+func (n Nutritionix) Lookup(ctx context.Context, ingredient models.Ingredient) (FoodNutrients, error) {
+	if err := n.Ready(); err != nil {
+		return FoodNutrients{}, err
 	}
 
-	nutrientsPerIngredient := make(map[string]map[string]float64)
-
-	for _, ingredient := range ingredients {
-		reqBody := NutritionixRequest{
-			Query: ingredient,
-		}
+	jsonData, err := json.Marshal(NutritionixRequest{Query: ingredient.Query()})
+	if err != nil {
+		return FoodNutrients{}, err
+	}
 
-		jsonData, err := json.Marshal(reqBody)
-		if err != nil {
-			return nil, err
-		}
+	req, err := http.NewRequestWithContext(withUpstream(ctx, n.Name()), "POST", n.Endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return FoodNutrients{}, err
+	}
 
-		req, err := http.NewRequest("POST", "https://trackapi.nutritionix.com/v2/natural/nutrients", bytes.NewBuffer(jsonData))
-		if err != nil {
-			return nil, err
-		}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-app-id", n.AppID)
+	req.Header.Set("x-app-key", n.AppKey)
 
-		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("x-app-id", appID)
-		req.Header.Set("x-app-key", appKey)
+	resp, err := httpClient(n.Client).Do(req)
+	if err != nil {
+		return FoodNutrients{}, err
+	}
+	defer resp.Body.Close()
 
-		client := &http.Client{}
-		resp, err := client.Do(req)
-		if err != nil {
-			return nil, err
+	if resp.StatusCode == http.StatusNotFound {
+		return FoodNutrients{}, ErrFoodNotFound // "We couldn't match any of your foods"
+	}
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := ioutil.ReadAll(resp.Body)
+		if quotaExceeded(resp.StatusCode, bodyBytes) {
+			return FoodNutrients{}, fmt.Errorf("nutritionix API error: %w: %s", ErrQuotaExceeded, string(bodyBytes))
 		}
-		defer resp.Body.Close()
+		return FoodNutrients{}, fmt.Errorf("nutritionix API error: %s", string(bodyBytes))
+	}
 
-		if resp.StatusCode != http.StatusOK {
-			bodyBytes, _ := ioutil.ReadAll(resp.Body)
-			return nil, fmt.Errorf("nutritionix API error: %s", string(bodyBytes))
-		}
+	var nutritionixResp NutritionixResponse
+	if err := json.NewDecoder(resp.Body).Decode(&nutritionixResp); err != nil {
+		return FoodNutrients{}, err
+	}
+	if len(nutritionixResp.Foods) == 0 {
+		return FoodNutrients{}, ErrFoodNotFound
+	}
 
-		var nutritionixResp NutritionixResponse
-		if err := json.NewDecoder(resp.Body).Decode(&nutritionixResp); err != nil {
-			return nil, err
-		}
+	food := nutritionixResp.Foods[0]
+	amounts := make(map[string]float64)
 
-		if len(nutritionixResp.Foods) == 0 {
-			nutrientsPerIngredient[ingredient] = map[string]float64{}
-			continue
+	for _, nutrient := range nutrients.All() {
+		for _, fn := range food.FullNutrients {
+			if fn.AttrID == nutrient.NutritionixID {
+				amounts[nutrient.Name] = fn.Value
+				break
+			}
 		}
+	}
+	return FoodNutrients{
+		Amounts: amounts,
+		Portion: models.Portion{
+			Quantity: food.ServingQty,
+			Unit:     food.ServingUnit,
+			Grams:    food.ServingWeightGrams,
+			Assumed:  ingredient.Quantity == 0,
+		},
+		Source: models.FoodSource{Provider: n.Name(), Description: food.FoodName},
+	}, nil
+}
 
-		food := nutritionixResp.Foods[0]
-		nutrients := make(map[string]float64)
+// Nutritionix answers 429, or 401 "usage limits exceeded" on the free tier, once the daily quota is spent
+func quotaExceeded(status int, body []byte) bool {
+	if status == http.StatusTooManyRequests {
+		return true
+	}
+	return status == http.StatusUnauthorized && bytes.Contains(bytes.ToLower(body), []byte("limit"))
+}
 
-		for nutrient, attrID := range nutrientMapping {
-			for _, fn := range food.FullNutrients {
-				if fn.AttrID == attrID {
-					nutrients[nutrient] = fn.Value
-					break
-				}
-			}
+/*=================================================================================================*/
+
+// ValidateNutrientMapping checks that every registered nutrient can be read from Nutritionix
+func ValidateNutrientMapping() error {
+	seen := make(map[int]string)
+	for _, nutrient := range nutrients.All() {
+		if nutrient.NutritionixID <= 0 {
+			return fmt.Errorf("nutritionix: no attr_id for %s", nutrient.Name)
+		}
+		if other, dup := seen[nutrient.NutritionixID]; dup {
+			return fmt.Errorf("nutritionix: attr_id %d mapped to both %s and %s", nutrient.NutritionixID, other, nutrient.Name)
 		}
-		nutrientsPerIngredient[ingredient] = nutrients
+		seen[nutrient.NutritionixID] = nutrient.Name
 	}
-	return nutrientsPerIngredient, nil
+	return nil
 }
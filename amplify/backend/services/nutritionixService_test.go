@@ -0,0 +1,48 @@
+// The-Nutrimancers-Codex/amplify/backend/services/nutritionixService_test.go
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/models"
+)
+
+func TestNutritionixLookupErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   error // nil: any other error
+	}{
+		{"unmatched food", http.StatusNotFound, `{"message": "We couldn't match any of your foods"}`, ErrFoodNotFound},
+		{"no foods", http.StatusOK, `{"foods": []}`, ErrFoodNotFound},
+		{"rate limited", http.StatusTooManyRequests, `{"message": "too many requests"}`, ErrQuotaExceeded},
+		{"free tier spent", http.StatusUnauthorized, `{"message": "usage limits exceeded"}`, ErrQuotaExceeded},
+		{"bad credentials", http.StatusUnauthorized, `{"message": "unauthorized"}`, nil},
+		{"server error", http.StatusInternalServerError, `{"message": "oops"}`, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.status)
+				w.Write([]byte(test.body))
+			}))
+			defer server.Close()
+			nutritionix := Nutritionix{Endpoint: server.URL, AppID: "id", AppKey: "key", Client: server.Client()}
+
+			_, err := nutritionix.Lookup(context.Background(), models.Ingredient{Food: "unobtainium"})
+			switch {
+			case err == nil:
+				t.Errorf("Lookup succeeded, want an error")
+			case test.want != nil && !errors.Is(err, test.want):
+				t.Errorf("Lookup = %v, want %v", err, test.want)
+			case test.want == nil && (errors.Is(err, ErrFoodNotFound) || errors.Is(err, ErrQuotaExceeded)):
+				t.Errorf("Lookup = %v, want a plain API error", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,275 @@
+// The-Nutrimancers-Codex/amplify/backend/services/provider.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/models"
+)
+
+// NutrientProvider looks up one free-text food and returns its nutrients by registry name,
+// in each nutrient's registry unit, for the portion it resolved
+type NutrientProvider interface {
+	Name() string
+	Lookup(ctx context.Context, ingredient models.Ingredient) (FoodNutrients, error)
+	Ready() error // nil once the provider can answer lookups, e.g. credentials are set
+}
+
+// FoodNutrients is one lookup's result
+type FoodNutrients struct {
+	Amounts map[string]float64 // registry name -> amount in the registry unit
+	Portion models.Portion
+	Source  models.FoodSource
+}
+
+// DefaultLookupConcurrency is how many of a meal's ingredients LookupEach looks up at once
+const DefaultLookupConcurrency = 4
+
+// ErrFoodNotFound is a provider finding nothing for the food; the analysis carries on without it
+var ErrFoodNotFound = errors.New("no foods found")
+
+// ErrQuotaExceeded is a provider refusing lookups until its usage limit resets
+var ErrQuotaExceeded = errors.New("provider quota exceeded")
+
+// ProviderOptions are everything NewNutrientProvider can build a provider from
+type ProviderOptions struct {
+	Nutritionix Nutritionix
+	FDC         FoodDataCentral
+	Dataset     DatasetOptions
+	Fallback    string // provider answering when the named one is out of quota or not configured
+
+	// Wrap (if set) decorates the named provider, not the fallback, e.g. with a cache
+	Wrap func(NutrientProvider) (NutrientProvider, error)
+}
+
+// DatasetOptions are the loaded dataset rows the offline provider indexes
+type DatasetOptions struct {
+	Foods         []models.FoodItem
+	MinConfidence float64 // 0 uses DefaultDatasetMinConfidence
+}
+
+var providers = map[string]func(ProviderOptions) (NutrientProvider, error){
+	"nutritionix": func(options ProviderOptions) (NutrientProvider, error) { return options.Nutritionix, nil },
+	"fdc":         func(options ProviderOptions) (NutrientProvider, error) { return options.FDC, nil },
+	"offline": func(options ProviderOptions) (NutrientProvider, error) {
+		if len(options.Dataset.Foods) == 0 {
+			return nil, errors.New("offline provider needs the dataset")
+		}
+		dataset := NewDataset(options.Dataset.Foods)
+		if options.Dataset.MinConfidence > 0 {
+			dataset.MinConfidence = options.Dataset.MinConfidence
+		}
+		return dataset, nil
+	},
+}
+
+// ProviderNames lists what NewNutrientProvider accepts
+func ProviderNames() []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewNutrientProvider builds the named provider
+func NewNutrientProvider(name string, options ProviderOptions) (NutrientProvider, error) {
+	build, ok := providers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown nutrient provider %q, expected one of %v", name, ProviderNames())
+	}
+	provider, err := build(options)
+	if err == nil && options.Wrap != nil {
+		provider, err = options.Wrap(provider)
+	}
+	if err != nil || options.Fallback == "" || options.Fallback == name {
+		return provider, err
+	}
+	fallback, err := NewNutrientProvider(options.Fallback, ProviderOptions{Nutritionix: options.Nutritionix, FDC: options.FDC, Dataset: options.Dataset})
+	if err != nil {
+		return nil, fmt.Errorf("fallback: %w", err)
+	}
+	return Fallback{Primary: provider, Secondary: fallback}, nil
+}
+
+// Fallback answers from Secondary while Primary is out of quota or not configured
+type Fallback struct {
+	Primary, Secondary NutrientProvider
+}
+
+func (f Fallback) Name() string {
+	return f.Primary.Name() + "+" + f.Secondary.Name()
+}
+
+// Ready as long as either provider is
+func (f Fallback) Ready() error {
+	primary := f.Primary.Ready()
+	if primary == nil {
+		return nil
+	}
+	if err := f.Secondary.Ready(); err != nil {
+		return fmt.Errorf("%v; fallback: %v", primary, err)
+	}
+	return nil
+}
+
+func (f Fallback) Lookup(ctx context.Context, ingredient models.Ingredient) (FoodNutrients, error) {
+	if f.Primary.Ready() != nil {
+		return f.Secondary.Lookup(ctx, ingredient)
+	}
+	result, err := f.Primary.Lookup(ctx, ingredient)
+	if errors.Is(err, ErrQuotaExceeded) {
+		log.Printf("%s: %v, falling back to %s", f.Primary.Name(), err, f.Secondary.Name())
+		return f.Secondary.Lookup(ctx, ingredient)
+	}
+	return result, err
+}
+
+/*=================================================================================================*/
+
+// Household measures in grams, water density for volumes
+var gramsPerUnit = map[string]float64{
+	"g": 1, "gram": 1, "grams": 1,
+	"kg": 1000, "kilogram": 1000, "kilograms": 1000,
+	"mg": 0.001,
+	"oz": 28.35, "ounce": 28.35, "ounces": 28.35,
+	"lb": 453.6, "lbs": 453.6, "pound": 453.6, "pounds": 453.6,
+	"ml": 1, "l": 1000, "liter": 1000, "litre": 1000,
+	"cup": 240, "cups": 240,
+	"tbsp": 15, "tablespoon": 15, "tablespoons": 15,
+	"tsp": 5, "teaspoon": 5, "teaspoons": 5,
+}
+
+// ingredientPortion is the ingredient in grams for providers that report per 100 g.
+// Counted items and unknown units assume 100 g each.
+func ingredientPortion(ingredient models.Ingredient) models.Portion {
+	if ingredient.Quantity <= 0 {
+		return models.Portion{Quantity: 100, Unit: "g", Grams: 100, Assumed: true}
+	}
+	if grams, ok := gramsPerUnit[ingredient.Unit]; ok {
+		return models.Portion{Quantity: ingredient.Quantity, Unit: ingredient.Unit, Grams: ingredient.Quantity * grams}
+	}
+	return models.Portion{Quantity: ingredient.Quantity, Unit: ingredient.Unit, Grams: ingredient.Quantity * 100, Assumed: true}
+}
+
+// Lookups are LookupEach's results by IngredientKeys
+type Lookups struct {
+	Nutrients map[string]map[string]float64
+	Portions  map[string]models.Portion
+	Sources   map[string]models.FoodSource
+}
+
+// LookupEach looks up the ingredients with their quantities, up to limit at a time, and returns
+// nutrients, portions and sources by IngredientKeys. onResolved (if set) is called with the key
+// as each lookup returns, one call at a time. A food the provider can't find gets no nutrients;
+// any other error cancels the lookups still running, as does ctx.
+func LookupEach(ctx context.Context, provider NutrientProvider, ingredients []models.Ingredient, limit int, onResolved func(key string, result FoodNutrients)) (Lookups, error) {
+	if limit < 1 {
+		limit = 1
+	}
+	keys := IngredientKeys(ingredients)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]FoodNutrients, len(ingredients))
+	slots := make(chan struct{}, limit)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex // guards firstErr, serializes onResolved
+		firstErr error
+	)
+	for i, ingredient := range ingredients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// A panicking provider fails this lookup rather than the whole process
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					mu.Lock()
+					defer mu.Unlock()
+					if firstErr == nil {
+						firstErr = fmt.Errorf("error fetching nutrient data for %s: panic: %v", ingredient.Food, recovered)
+						cancel()
+					}
+				}
+			}()
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				return
+			}
+
+			result, err := provider.Lookup(ctx, ingredient)
+			if errors.Is(err, ErrFoodNotFound) {
+				result, err = FoodNutrients{Amounts: map[string]float64{}, Portion: ingredient.Portion()}, nil
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("error fetching nutrient data for %s: %w", ingredient.Food, err)
+					cancel()
+				}
+				return
+			}
+			results[i] = result
+			if onResolved != nil && firstErr == nil {
+				onResolved(keys[i], result)
+			}
+		}()
+	}
+	wg.Wait()
+	if firstErr == nil {
+		firstErr = ctx.Err() // the caller's, ours is only cancelled with firstErr set
+	}
+	if firstErr != nil {
+		return Lookups{}, firstErr
+	}
+
+	lookups := Lookups{
+		Nutrients: make(map[string]map[string]float64),
+		Portions:  make(map[string]models.Portion),
+		Sources:   make(map[string]models.FoodSource),
+	}
+	for i, key := range keys {
+		lookups.Nutrients[key] = results[i].Amounts
+		lookups.Portions[key] = results[i].Portion
+		if results[i].Source.Provider != "" {
+			lookups.Sources[key] = results[i].Source
+		}
+	}
+	return lookups, nil
+}
+
+// IngredientKeys names each ingredient by its food, numbering repeats - "egg", "egg (2)" - so a
+// food listed twice keeps both entries' results
+func IngredientKeys(ingredients []models.Ingredient) []string {
+	keys := make([]string, len(ingredients))
+	used := make(map[string]bool, len(ingredients))
+	for i, ingredient := range ingredients {
+		key := ingredient.Food
+		for n := 2; used[key]; n++ {
+			key = fmt.Sprintf("%s (%d)", ingredient.Food, n)
+		}
+		used[key] = true
+		keys[i] = key
+	}
+	return keys
+}
+
+// LookupFoods looks up free-text foods at the provider's default serving
+func LookupFoods(ctx context.Context, provider NutrientProvider, foods []string) (map[string]map[string]float64, error) {
+	ingredients := make([]models.Ingredient, len(foods))
+	for i, food := range foods {
+		ingredients[i] = models.Ingredient{Food: food}
+	}
+	lookups, err := LookupEach(ctx, provider, ingredients, DefaultLookupConcurrency, nil)
+	return lookups.Nutrients, err
+}
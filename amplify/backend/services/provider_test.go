@@ -0,0 +1,67 @@
+// The-Nutrimancers-Codex/amplify/backend/services/provider_test.go
+package services
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/models"
+)
+
+// echoProvider finds every food, with its stated portion and as much iron as its quantity
+type echoProvider struct{}
+
+func (echoProvider) Name() string {
+	return "echo"
+}
+
+func (echoProvider) Ready() error {
+	return nil
+}
+
+func (echoProvider) Lookup(ctx context.Context, ingredient models.Ingredient) (FoodNutrients, error) {
+	return FoodNutrients{
+		Amounts: map[string]float64{"Iron": ingredient.Quantity},
+		Portion: ingredient.Portion(),
+		Source:  models.FoodSource{Provider: "echo", Description: ingredient.Query()},
+	}, nil
+}
+
+/*=================================================================================================*/
+
+func TestLookupEachRepeatedFood(t *testing.T) {
+	ingredients := []models.Ingredient{
+		{Food: "egg", Quantity: 2},
+		{Food: "rice", Quantity: 200, Unit: "g"},
+		{Food: "egg", Quantity: 1, Unit: "large"},
+		{Food: "egg (2)", Quantity: 3}, // a food already named like a numbered repeat
+	}
+	wantKeys := []string{"egg", "rice", "egg (2)", "egg (2) (2)"}
+	if keys := IngredientKeys(ingredients); !slices.Equal(keys, wantKeys) {
+		t.Fatalf("IngredientKeys = %q, want %q", keys, wantKeys)
+	}
+
+	var resolved []string
+	lookups, err := LookupEach(context.Background(), echoProvider{}, ingredients, 2, func(key string, result FoodNutrients) {
+		resolved = append(resolved, key)
+	})
+	if err != nil {
+		t.Fatalf("LookupEach: %v", err)
+	}
+	slices.Sort(resolved)
+	if sorted := slices.Sorted(slices.Values(wantKeys)); !slices.Equal(resolved, sorted) {
+		t.Errorf("onResolved keys = %q, want %q", resolved, sorted)
+	}
+	for i, key := range wantKeys {
+		if got := lookups.Nutrients[key]["Iron"]; got != ingredients[i].Quantity {
+			t.Errorf("%s iron = %g, want %g", key, got, ingredients[i].Quantity)
+		}
+		if got := lookups.Portions[key]; got != ingredients[i].Portion() {
+			t.Errorf("%s portion = %+v, want %+v", key, got, ingredients[i].Portion())
+		}
+		if got := lookups.Sources[key].Description; got != ingredients[i].Query() {
+			t.Errorf("%s source = %q, want %q", key, got, ingredients[i].Query())
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// The-Nutrimancers-Codex/amplify/backend/streamHandlers.go
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/analysis"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/models"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/utils"
+)
+
+// processFoodHandler as Server-Sent Events: one event per finished stage, then the full result.
+// Failures before the first event get a plain JSON error; after it, an error event.
+func (s *Server) processFoodStreamHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Streaming not supported")
+		return
+	}
+
+	var req models.ProcessFoodRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Error decoding request: "+err.Error())
+		return
+	}
+	// Rejected as plain JSON before the stream opens
+	if err := analysis.Validate(req); err != nil {
+		utils.RespondWithError(w, err.Status, err.Message)
+		return
+	}
+
+	extendWriteDeadline(w)
+	started := false
+	send := func(event models.AnalysisEvent, data any) {
+		if !started {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("Connection", "keep-alive")
+			w.Header().Set("X-Accel-Buffering", "no") // keep proxies from holding events back
+			w.WriteHeader(http.StatusOK)
+			started = true
+		}
+		writeEvent(w, event, data)
+		flusher.Flush()
+	}
+
+	response, analysisErr := s.analyzer.AnalyzeMeal(r.Context(), req, send)
+	switch {
+	case analysisErr == nil:
+		send(models.EventResult, response)
+	case started:
+		send(models.EventError, models.AnalysisFailed{Error: analysisErr.Message, Status: analysisErr.Status})
+	default:
+		utils.RespondWithError(w, analysisErr.Status, analysisErr.Message)
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event models.AnalysisEvent, data any) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		payload, _ = json.Marshal(models.AnalysisFailed{Error: err.Error(), Status: http.StatusInternalServerError})
+		event = models.EventError
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+}
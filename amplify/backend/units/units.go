@@ -0,0 +1,120 @@
+// The-Nutrimancers-Codex/amplify/backend/units/units.go
+package units
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Unit string
+
+const (
+	Gram          Unit = "g"
+	Milligram     Unit = "mg"
+	Microgram     Unit = "µg"
+	International Unit = "IU" // nutrient-specific, see iuFactors
+	Kilocalorie   Unit = "kcal"
+	Kilojoule     Unit = "kJ"
+)
+
+// Quantity is an amount tagged with its unit
+type Quantity struct {
+	Value float64 `json:"value"`
+	Unit  Unit    `json:"unit"`
+}
+
+func (q Quantity) String() string {
+	return fmt.Sprintf("%g %s", q.Value, q.Unit)
+}
+
+/*=================================================================================================*/
+
+// Mass units relative to 1 mg
+var massFactors = map[Unit]float64{
+	Gram:      1000.0,
+	Milligram: 1.0,
+	Microgram: 0.001,
+}
+
+// Energy units relative to 1 kcal
+var energyFactors = map[Unit]float64{
+	Kilocalorie: 1.0,
+	Kilojoule:   1.0 / 4.184,
+}
+
+// IU -> mass, per nutrient (NIH ODS / FDA labeling factors)
+var iuFactors = map[string]Quantity{
+	"Vitamin A": {Value: 0.3, Unit: Microgram},   // 1 IU = 0.3 µg RAE (retinol)
+	"Vitamin D": {Value: 0.025, Unit: Microgram}, // 1 IU = 0.025 µg cholecalciferol
+	"Vitamin E": {Value: 0.67, Unit: Milligram},  // 1 IU = 0.67 mg α-tocopherol (natural RRR form)
+}
+
+// Spellings seen across Nutritionix, USDA CSVs and hand-written tables
+var aliases = map[string]Unit{
+	"g":    Gram,
+	"mg":   Milligram,
+	"µg":   Microgram, // micro sign U+00B5
+	"μg":   Microgram, // greek mu U+03BC
+	"ug":   Microgram,
+	"mcg":  Microgram,
+	"iu":   International,
+	"kcal": Kilocalorie,
+	"kj":   Kilojoule,
+}
+
+/*=================================================================================================*/
+
+// Parse normalizes a unit spelling ("UG", "mcg", "MG") to a Unit
+func Parse(unit string) (Unit, error) {
+	if u, ok := aliases[strings.ToLower(strings.TrimSpace(unit))]; ok {
+		return u, nil
+	}
+	return "", fmt.Errorf("units: unknown unit %q", unit)
+}
+
+// Convert expresses a nutrient's quantity in another unit; IU needs the nutrient name
+func Convert(nutrient string, q Quantity, to Unit) (Quantity, error) {
+	if q.Unit == to {
+		return q, nil
+	}
+	if q.Unit == International {
+		factor, ok := iuFactors[nutrient]
+		if !ok {
+			return Quantity{}, fmt.Errorf("units: no IU conversion for %s", nutrient)
+		}
+		q = Quantity{Value: q.Value * factor.Value, Unit: factor.Unit}
+		return Convert(nutrient, q, to)
+	}
+	if to == International {
+		factor, ok := iuFactors[nutrient]
+		if !ok {
+			return Quantity{}, fmt.Errorf("units: no IU conversion for %s", nutrient)
+		}
+		mass, err := Convert(nutrient, q, factor.Unit)
+		if err != nil {
+			return Quantity{}, err
+		}
+		return Quantity{Value: mass.Value / factor.Value, Unit: International}, nil
+	}
+
+	for _, factors := range []map[Unit]float64{massFactors, energyFactors} {
+		from, fromOK := factors[q.Unit]
+		target, toOK := factors[to]
+		if fromOK && toOK {
+			return Quantity{Value: q.Value * from / target, Unit: to}, nil
+		}
+		if fromOK || toOK {
+			return Quantity{}, fmt.Errorf("units: cannot convert %s to %s", q.Unit, to)
+		}
+	}
+	return Quantity{}, fmt.Errorf("units: unknown unit %q", q.Unit)
+}
+
+// ToMilligrams is Convert to mg, the unit all target math is done in
+func ToMilligrams(nutrient string, q Quantity) (float64, error) {
+	mg, err := Convert(nutrient, q, Milligram)
+	if err != nil {
+		return 0, err
+	}
+	return mg.Value, nil
+}
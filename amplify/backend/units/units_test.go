@@ -0,0 +1,110 @@
+// The-Nutrimancers-Codex/amplify/backend/units/units_test.go
+package units
+
+import (
+	"math"
+	"testing"
+)
+
+// Published factors: NIH ODS fact sheets and the FDA's 2016 labeling rule
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name     string
+		nutrient string
+		from     Quantity
+		to       Unit
+		want     float64
+	}{
+		{"vitamin A IU to µg RAE", "Vitamin A", Quantity{1, International}, Microgram, 0.3},
+		{"vitamin A 3333 IU is 1000 µg", "Vitamin A", Quantity{3333, International}, Microgram, 999.9},
+		{"vitamin A µg RAE to IU", "Vitamin A", Quantity{900, Microgram}, International, 3000},
+		{"vitamin E IU to mg", "Vitamin E", Quantity{1, International}, Milligram, 0.67},
+		{"vitamin E 22.4 IU is 15 mg", "Vitamin E", Quantity{22.4, International}, Milligram, 15.008},
+		{"vitamin D IU to µg", "Vitamin D", Quantity{1, International}, Microgram, 0.025},
+		{"vitamin D 600 IU is 15 µg", "Vitamin D", Quantity{600, International}, Microgram, 15},
+		{"vitamin D µg to IU", "Vitamin D", Quantity{20, Microgram}, International, 800},
+		{"vitamin D IU to mg", "Vitamin D", Quantity{400, International}, Milligram, 0.01},
+		{"g to mg", "Protein", Quantity{2.5, Gram}, Milligram, 2500},
+		{"µg to mg", "Selenium", Quantity{55, Microgram}, Milligram, 0.055},
+		{"mg to µg", "Copper", Quantity{0.9, Milligram}, Microgram, 900},
+		{"kJ to kcal", "Energy", Quantity{4.184, Kilojoule}, Kilocalorie, 1},
+		{"same unit", "Iron", Quantity{8, Milligram}, Milligram, 8},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Convert(test.nutrient, test.from, test.to)
+			if err != nil {
+				t.Fatalf("Convert(%s, %v, %s): %v", test.nutrient, test.from, test.to, err)
+			}
+			if got.Unit != test.to || math.Abs(got.Value-test.want) > 1e-9*math.Max(1, test.want) {
+				t.Errorf("Convert(%s, %v, %s) = %v, want %g %s", test.nutrient, test.from, test.to, got, test.want, test.to)
+			}
+		})
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		nutrient string
+		from     Quantity
+		to       Unit
+	}{
+		{"IU for a nutrient without a factor", "Vitamin C", Quantity{100, International}, Milligram},
+		{"to IU for a nutrient without a factor", "Calcium", Quantity{100, Milligram}, International},
+		{"unknown source unit", "Iron", Quantity{1, Unit("grain")}, Milligram},
+		{"unknown target unit", "Iron", Quantity{1, Milligram}, Unit("grain")},
+		{"mass to energy", "Energy", Quantity{1, Gram}, Kilocalorie},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got, err := Convert(test.nutrient, test.from, test.to); err == nil {
+				t.Errorf("Convert(%s, %v, %s) = %v, want an error", test.nutrient, test.from, test.to, got)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		spelling string
+		want     Unit
+	}{
+		{"mcg", Microgram},
+		{"MCG", Microgram},
+		{"µg", Microgram}, // micro sign
+		{"μg", Microgram}, // greek mu
+		{"UG", Microgram},
+		{" mg ", Milligram},
+		{"G", Gram},
+		{"IU", International},
+		{"kJ", Kilojoule},
+		{"KCAL", Kilocalorie},
+	}
+	for _, test := range tests {
+		got, err := Parse(test.spelling)
+		if err != nil || got != test.want {
+			t.Errorf("Parse(%q) = %q, %v; want %q", test.spelling, got, err, test.want)
+		}
+	}
+
+	for _, spelling := range []string{"", "grain", "oz"} {
+		if got, err := Parse(spelling); err == nil {
+			t.Errorf("Parse(%q) = %q, want an error", spelling, got)
+		}
+	}
+}
+
+// mcg, ug and µg values convert identically once parsed
+func TestMicrogramAliases(t *testing.T) {
+	for _, spelling := range []string{"mcg", "ug", "µg", "μg"} {
+		unit, err := Parse(spelling)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", spelling, err)
+		}
+		mg, err := ToMilligrams("Vitamin B12", Quantity{2.4, unit})
+		if err != nil || math.Abs(mg-0.0024) > 1e-12 {
+			t.Errorf("ToMilligrams(2.4 %s) = %g, %v; want 0.0024", spelling, mg, err)
+		}
+	}
+}
@@ -9,8 +9,8 @@ import (
 
 // json error
 func RespondWithError(w http.ResponseWriter, code int, message string) {
-	w.WriteHeader(code)
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
 	response := map[string]string{"error": message}
 	jsonResp, _ := json.Marshal(response)
 	w.Write(jsonResp)
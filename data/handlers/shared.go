@@ -0,0 +1,34 @@
+// The-Nutrimancers-Codex/backend/handlers/shared.go
+package handlers
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/analysis"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/config"
+	"github.com/RidwanSharkar/The-Nutrimancers-Codex/amplify/backend/models"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Loaded once per cold start - config, thresholds, the dataset and the nutrient provider -
+// and reused by every invocation the container serves
+var analyzer, analyzerErr = loadAnalyzer()
+
+func loadAnalyzer() (*analysis.Analyzer, error) {
+	cfg, err := config.Load(nil, os.LookupEnv)
+	if err != nil {
+		return nil, err
+	}
+	return analysis.New(cfg, nil, nil)
+}
+
+// json error, as utils.RespondWithError writes it for the server
+func respondWithError(code int, message string) (events.APIGatewayProxyResponse, error) {
+	body, _ := json.Marshal(models.ErrorResponse{Error: message})
+	return events.APIGatewayProxyResponse{
+		StatusCode: code,
+		Body:       string(body),
+		Headers:    map[string]string{"Content-Type": "application/json"},
+	}, nil
+}
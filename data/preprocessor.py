@@ -1,106 +1,120 @@
 import pandas as pd
 import os
 
-# Load datasets
-data_dir = r'C:\Users\Lenovo\Desktop\Bioessence\data'
-food_df = pd.read_csv(os.path.join(data_dir, 'FoundationalFood.csv'))
-nutrient_df = pd.read_csv(os.path.join(data_dir, 'FoundationalNutrient.csv'))
-food_nutrient_df = pd.read_csv(os.path.join(data_dir, 'FoundationalFoodNutrient.csv'), low_memory=False)
-
-# Inspect
-print(food_nutrient_df.dtypes)
-print(food_nutrient_df.iloc[:, 9].unique())
+# Builds amplify/backend/machinist/dataset.csv from the FoodData Central CSV downloads
+# (https://fdc.nal.usda.gov/download-datasets): the Foundation Foods and SR Legacy food.csv,
+# nutrient.csv and food_nutrient.csv, saved here with a Foundational / Legacy prefix.
+data_dir = os.path.dirname(os.path.abspath(__file__))
+output_file = os.path.join(data_dir, '..', 'amplify', 'backend', 'machinist', 'dataset.csv')
+sources = ['Foundational', 'Legacy']
 
 
+# Dataset column -> USDA nutrient_nbr, in the order of the registry (amplify/backend/nutrients/registry.go).
+# Numbers, not names: the two releases spell the same nutrient differently ("Sugars, Total" / "Total Sugars").
+# Later numbers are fallbacks for foods that only report those.
 nutrientMapping = {
     # Essential Ions
-    "Potassium": "Potassium, K",
-    "Sodium": "Sodium, Na",
-    "Calcium": "Calcium, Ca",
-    "Phosphorus": "Phosphorus, P",
-    "Magnesium": "Magnesium, Mg",
-    "Iron": "Iron, Fe",
-    "Zinc": "Zinc, Zn",
-    "Manganese": "Manganese, Mn",
-    "Copper": "Copper, Cu",
-    "Selenium": "Selenium, Se",
+    "Potassium": ["306"],
+    "Chloride": ["302"],
+    "Sodium": ["307"],
+    "Calcium": ["301"],
+    "Phosphorus": ["305"],
+    "Magnesium": ["304"],
+    "Iron": ["303"],
+    "Zinc": ["309"],
+    "Manganese": ["315"],
+    "Copper": ["312"],
+    "Selenium": ["317"],
+    "Iodine": ["314"],
+    "Chromium": ["310"],
+    "Molybdenum": ["316"],
 
     # Essential Amino Acids
-    "Histidine": "Histidine",
-    "Isoleucine": "Isoleucine",
-    "Leucine": "Leucine",
-    "Lysine": "Lysine",
-    "Methionine": "Methionine",
-    "Phenylalanine": "Phenylalanine",
-    "Threonine": "Threonine",
-    "Tryptophan": "Tryptophan",
-    "Valine": "Valine",
+    "Histidine": ["512"],
+    "Isoleucine": ["503"],
+    "Leucine": ["504"],
+    "Lysine": ["505"],
+    "Methionine": ["506"],
+    "Phenylalanine": ["508"],
+    "Threonine": ["502"],
+    "Tryptophan": ["501"],
+    "Valine": ["510"],
 
     # Essential Omega Fatty Acids
-    "Alpha-Linolenic Acid": "PUFA 18:3 n-3 c,c,c (ALA)",
-    "Linoleic Acid": "PUFA 18:2 n-6 c,c",
-    "EPA": "PUFA 20:5 n-3 (EPA)",
-    "DHA": "PUFA 22:6 n-3 (DHA)",
+    "Alpha-Linolenic Acid": ["851", "619"],  # 18:3 n-3 c,c,c, then 18:3 undifferentiated
+    "Linoleic Acid": ["675", "618"],         # 18:2 n-6 c,c, then 18:2 undifferentiated
+    "EPA": ["629"],
+    "DHA": ["621"],
 
     # Vitamins
-    "Vitamin A": "Vitamin A, RAE",
-    "Vitamin B1": "Thiamin",
-    "Vitamin B2": "Riboflavin",
-    "Vitamin B3": "Niacin",
-    "Vitamin B5": "Pantothenic acid",
-    "Vitamin B6": "Vitamin B-6",
-    "Vitamin B9": "Folate, total",
-    "Vitamin B12": "Vitamin B-12",
-    "Vitamin C": "Vitamin C, total ascorbic acid",
-    "Vitamin D": "Vitamin D (D2 + D3)",
-    "Vitamin E": "Vitamin E (alpha-tocopherol)",
-    "Vitamin K": "Vitamin K (phylloquinone)",
-
-    "Choline": "Choline, total"
+    "Vitamin A": ["320"],    # RAE
+    "Vitamin B1": ["404"],
+    "Vitamin B2": ["405"],
+    "Vitamin B3": ["406"],
+    "Vitamin B5": ["410"],
+    "Vitamin B6": ["415"],
+    "Vitamin B7": ["416"],   # Biotin
+    "Vitamin B9": ["417"],   # Folate, total
+    "Vitamin B12": ["418"],
+    "Vitamin C": ["401"],
+    "Vitamin D": ["328"],    # D2 + D3 in µg - the offline provider converts to IU
+    "Vitamin E": ["323"],    # alpha-tocopherol
+    "Vitamin K": ["430"],    # phylloquinone
+
+    "Choline": ["421"],
+
+    # Macronutrients (kcal / g)
+    "Energy": ["208", "958", "957"],  # kcal, then the Atwater energies Foundation foods report instead
+    "Protein": ["203"],
+    "Carbohydrate": ["205", "205.2"],  # by difference, then by summation
+    "Fat": ["204"],
+    "Fiber": ["291"],
+    "Sugars": ["269", "269.3"],
 }
 
-# Filter
-usda_nutrient_names = list(nutrientMapping.values())
-filtered_nutrient_df = nutrient_df[nutrient_df['name'].isin(usda_nutrient_names)]
-nutrient_ids = filtered_nutrient_df['id'].tolist()
-filtered_food_nutrient_df = food_nutrient_df[food_nutrient_df['nutrient_id'].isin(nutrient_ids)]
-
-# Merge
-merged_df = filtered_food_nutrient_df.merge(
-    filtered_nutrient_df[['id', 'name']],
-    left_on='nutrient_id',
-    right_on='id',
-    suffixes=('_fn', '_nutrient')
-)
-merged_df = merged_df.merge(food_df[['fdc_id', 'description']], on='fdc_id')
-
-# Pivot
-pivot_df = merged_df.pivot_table(
-    index=['fdc_id', 'description'],
-    columns='name',
-    values='amount',
-    aggfunc='first'
-)
-pivot_df.reset_index(inplace=True)
-
-# Rename
-reverse_mapping = {v: k for k, v in nutrientMapping.items()}
-pivot_df.rename(columns=reverse_mapping, inplace=True)
-
-# Reorder
-final_columns = [
-    "fdc_id", "description", "Potassium", "Sodium", "Calcium", "Phosphorus", "Magnesium",
-    "Iron", "Zinc", "Manganese", "Copper", "Selenium", "Histidine", "Isoleucine", 
-    "Leucine", "Lysine", "Methionine", "Phenylalanine", "Threonine", "Tryptophan", "Valine", 
-    "Alpha-Linolenic Acid", "Linoleic Acid", "EPA", "DHA", "Vitamin A", "Vitamin B1", 
-    "Vitamin B2", "Vitamin B3", "Vitamin B5", "Vitamin B6", "Vitamin B9", "Vitamin B12", 
-    "Vitamin C", "Vitamin D", "Vitamin E", "Vitamin K", "Choline"
-]
-
-# Fill
-pivot_df = pivot_df.reindex(columns=final_columns)
 
-# Output
-output_file = 'dataset.csv'
-pivot_df.to_csv(output_file, index=False)
+def load(prefix):
+    food_df = pd.read_csv(os.path.join(data_dir, prefix + 'Food.csv'))
+    nutrient_df = pd.read_csv(os.path.join(data_dir, prefix + 'Nutrient.csv'), dtype={'nutrient_nbr': str})
+    food_nutrient_df = pd.read_csv(os.path.join(data_dir, prefix + 'FoodNutrient.csv'), low_memory=False)
+
+    # nutrient_nbr is written "328.0" in some releases
+    nutrient_df['nutrient_nbr'] = nutrient_df['nutrient_nbr'].str.replace(r'\.0$', '', regex=True)
+    merged_df = food_nutrient_df.merge(nutrient_df[['id', 'nutrient_nbr']], left_on='nutrient_id', right_on='id')
+    merged_df = merged_df.merge(food_df[['fdc_id', 'description']], on='fdc_id')
+
+    # Pivot
+    pivot_df = merged_df.pivot_table(
+        index=['fdc_id', 'description'],
+        columns='nutrient_nbr',
+        values='amount',
+        aggfunc='first'
+    )
+
+    # First number each food reports, per column
+    columns = {}
+    for column, numbers in nutrientMapping.items():
+        series = pd.Series(float('nan'), index=pivot_df.index)
+        for number in numbers:
+            if number in pivot_df.columns:
+                series = series.fillna(pivot_df[number])
+        columns[column] = series
+    return pd.DataFrame(columns).reset_index()
+
+
+dataset_df = pd.concat([load(prefix) for prefix in sources], ignore_index=True)
+
+# Reorder - the registry order the loader checks the header against
+final_columns = ["fdc_id", "description"] + list(nutrientMapping.keys())
+dataset_df = dataset_df.reindex(columns=final_columns)
+
+# Inspect - columns no food reports are a mapping problem, not sparse data
+for column in nutrientMapping:
+    reported = dataset_df[column].notna().sum()
+    print(f"{column}: {reported} foods")
+    if reported == 0:
+        raise SystemExit(f"no food reports {column}; check its nutrient numbers")
 
+# Output
+dataset_df.to_csv(output_file, index=False)
+print("Wrote", os.path.normpath(output_file))
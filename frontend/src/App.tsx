@@ -4,7 +4,8 @@ import React, { useState, useMemo } from 'react';
 import IngredientsPanel from './grimoire/IngredientsPanel';
 import SuggestionPanel from './grimoire/SuggestionPanel';
 import OrbsPanel from './grimoire/OrbsPanel';
-import { processFood } from './services/backendService';
+import { processFoodStream, lookupFood, isLoggedIn, logIn, register } from './services/backendService';
+import type { ProcessFoodResponse } from './services/backendService';
 import './App.css';
 
 const nutrientCategoryList = {
@@ -131,13 +132,6 @@ const categorizeNutrients = (
   return categorized;
 };
 
-type ProcessFoodResponse = {
-  ingredients: string[];
-  nutrients: { [ingredient: string]: { [nutrient: string]: number } };
-  missingNutrients: string[];
-  suggestions: string[];
-};
-
 const App: React.FC = () => {
   const [food, setFood] = useState<string>('');
   const [ingredients, setIngredients] = useState<string[]>([]);
@@ -153,27 +147,71 @@ const App: React.FC = () => {
 
   /*=================================================================================================*/
 
+  // Account - analysis requires a bearer token
+  const [loggedIn, setLoggedIn] = useState<boolean>(isLoggedIn());
+  const [username, setUsername] = useState<string>('');
+  const [password, setPassword] = useState<string>('');
+
+  const handleLogIn = async (createAccount: boolean) => {
+    setLoading(true);
+    setError(null);
+    try {
+      if (createAccount) {
+        await register(username, password);
+      }
+      await logIn(username, password);
+      setPassword('');
+      setLoggedIn(true);
+    } catch (err: unknown) {
+      setError(err instanceof Error ? err.message : 'An unexpected error occurred.');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  /*=================================================================================================*/
+
   const [baseNutrients, setBaseNutrients] = useState<{ [key: string]: number }>({});
   const [originalMissingNutrients, setOriginalMissingNutrients] = useState<string[]>([]);
 
+  // Streamed: the orbs fill in as each ingredient's lookup returns, then settle on the totals
   const handleFoodSubmit = async () => {
     if (food.trim()) {
       setLoading(true);
       setError(null);
+      setIngredients([]);
+      setNutrients({});
+      setMissingNutrients([]);
+      setOriginalMissingNutrients([]);
+      setSuggestions([]);
+      setSelectedIngredient('Full Meal');
+      setSelectedNutrientData({});
+      setHighlightedNutrients([]);
       try {
-        const response: ProcessFoodResponse = await processFood(food.trim());
-        console.log('Received ingredients:', response.ingredients);
-        console.log('Received nutrients:', response.nutrients);
-        console.log('Received missingNutrients:', response.missingNutrients);
-        console.log('Received suggestions:', response.suggestions);
+        const response: ProcessFoodResponse = await processFoodStream(food.trim(), {
+          onIngredients: (event) => setIngredients(event.ingredients || []),
+          onIngredient: (event) => {
+            setNutrients((previous) => ({ ...previous, [event.ingredient]: event.nutrients }));
+            setSelectedNutrientData((previous) => {
+              const running = { ...previous };
+              for (const nutrient in event.nutrients) {
+                running[nutrient] = (running[nutrient] || 0) + event.nutrients[nutrient];
+              }
+              return running;
+            });
+          },
+          onTotals: (event) => {
+            setSelectedNutrientData(event.nutrients);
+            setMissingNutrients(event.missingNutrients || []);
+            setOriginalMissingNutrients(event.missingNutrients || []);
+          },
+          onRecommendations: (event) => setSuggestions(event.suggestions || []),
+        });
         setIngredients(response.ingredients || []);
         setNutrients(response.nutrients || {});
         setMissingNutrients(response.missingNutrients || []);
         setOriginalMissingNutrients(response.missingNutrients || []);
         setSuggestions(response.suggestions || []);
-        setSelectedIngredient('Full Meal');
-        setSelectedNutrientData({});
-        setHighlightedNutrients([]);
 
         const totalNutrients: { [key: string]: number } = {};
         (response.ingredients || []).forEach((ing) => {
@@ -193,6 +231,7 @@ const App: React.FC = () => {
         } else {
           setError('An unexpected error occurred.');
         }
+        setLoggedIn(isLoggedIn());
       } finally {
         setLoading(false);
       }
@@ -229,22 +268,7 @@ const App: React.FC = () => {
 
   const handleRecommendationClick = async (suggestion: string) => {
     try {
-      const response = await fetch('https://Nutrimancer-env.eba-mhnjc34h.us-east-1.elasticbeanstalk.com/fetch-nutrient-data', {
-        method: 'POST',
-        headers: {
-          'Content-Type': 'application/json',
-        },
-        body: JSON.stringify({
-          foodDescription: suggestion,
-          currentNutrients: baseNutrients,
-        }),
-      });
-
-      if (!response.ok) {
-        const errorText = await response.text();
-        throw new Error(`Server Error: ${errorText}`);
-      }
-      const data = await response.json();
+      const data = await lookupFood(suggestion, baseNutrients);
       const updatedNutrients = data.nutrients || {};
       const changedNutrients = data.changedNutrients || [];
 
@@ -279,7 +303,45 @@ const App: React.FC = () => {
             The Nutrimancer's Codex Vol. II
           </h1>
 
+          {/* Log In Section */}
+          {!loggedIn && (
+            <div className="flex justify-center mb-8">
+              <input
+                type="text"
+                className="w-1/6 p-3 rounded-md focus:outline-none bg-white text-black mr-3 text-xl"
+                placeholder="Username"
+                value={username}
+                onChange={(e) => setUsername(e.target.value)}
+              />
+              <input
+                type="password"
+                className="w-1/6 p-3 rounded-md focus:outline-none bg-white text-black mr-5 text-xl"
+                placeholder="Password"
+                value={password}
+                onChange={(e) => setPassword(e.target.value)}
+                onKeyPress={(e) => {
+                  if (e.key === 'Enter') handleLogIn(false);
+                }}
+              />
+              <button
+                onClick={() => handleLogIn(false)}
+                disabled={loading || !username.trim() || !password}
+                className="p-3 bg-green-500 text-white rounded-md text-xl mr-3 hover:bg-green-600"
+              >
+                Log In
+              </button>
+              <button
+                onClick={() => handleLogIn(true)}
+                disabled={loading || !username.trim() || !password}
+                className="p-3 bg-blue-500 text-white rounded-md text-xl hover:bg-blue-600"
+              >
+                Register
+              </button>
+            </div>
+          )}
+
           {/* Food Input Section */}
+          {loggedIn && (
           <div className="flex justify-center mb-8">
             <input
               type="text"
@@ -301,12 +363,13 @@ const App: React.FC = () => {
               {loading ? 'Extracting...' : 'Extract Essence'}
             </button>
           </div>
+          )}
           {error && <p className="text-center text-red-500 mb-4">{error}</p>}
 
 
 
           {/* Panels Container */}
-          {!loading && !error && ingredients && ingredients.length > 0 && (
+          {!error && ingredients && ingredients.length > 0 && (
             <div className="flex justify-center gap-8 w-full px-4">
 
 
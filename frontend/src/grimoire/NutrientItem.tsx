@@ -14,7 +14,8 @@ const NutrientItem: React.FC<NutrientItemProps> = ({
   classificationColor,
 }) => {
   const isPresent = percentage !== undefined && percentage > 0;
-  const percentageText = isPresent ? `${percentage.toFixed(2)}%` : '';
+  // Backend reports uncapped totals (see excessNutrients) - clamp for display only
+  const percentageText = isPresent ? `${Math.min(percentage, 100).toFixed(2)}%` : '';
 
   // Empty/Missing Nutrient Color
   const textColorClass = isPresent ? classificationColor : 'text-gray-400';
@@ -0,0 +1,309 @@
+// The-Nutrimancers-Codex/frontend/src/services/api.ts
+// Generated from the backend's Go types by `go run ./cmd/openapi` - do not edit.
+
+export interface Account {
+  id: string;
+  username: string;
+}
+
+export interface Admin {
+  token: string;
+}
+
+export interface AminoAcidScore {
+  limitingAminoAcid?: string;
+  proteinG: number;
+  ratios: { [key: string]: number };
+  score: number | null;
+  unreported?: string[];
+}
+
+export interface Analysis {
+  batchWorkers: number;
+  defaultThreshold: number;
+  topN: number;
+}
+
+export interface AnalysisFailed {
+  error: string;
+  status: number;
+}
+
+export interface BatchItemResult {
+  error?: string;
+  index: number;
+  result?: ProcessFoodResponse | null;
+  status: number;
+}
+
+export interface BatchProcessFoodRequest {
+  items: ProcessFoodRequest[];
+}
+
+export interface BatchProcessFoodResponse {
+  failed: number;
+  results: BatchItemResult[];
+  succeeded: number;
+}
+
+export interface Cache {
+  notFoundTtl: string;
+  persist: boolean;
+  size: number;
+  ttl: string;
+}
+
+export interface Config {
+  admin: Admin;
+  analysis: Analysis;
+  cache: Cache;
+  data: Data;
+  fdc: FDC;
+  gemini: Gemini;
+  nutritionix: Nutritionix;
+  provider: Provider;
+  server: Server;
+}
+
+export interface Credentials {
+  password: string;
+  username: string;
+}
+
+export interface DailyTotals {
+  date: string;
+  entryIds: string[];
+  excessNutrients: ExcessNutrient[];
+  gaps: NutrientGap[];
+  macros: Macros;
+  missingNutrients: string[];
+  nutrients: { [key: string]: number };
+  profile: Profile;
+}
+
+export interface Data {
+  databasePath: string;
+  datasetPath: string;
+  thresholdsPath: string;
+}
+
+export interface ErrorResponse {
+  error: string;
+}
+
+export interface ExcessNutrient {
+  nutrient: string;
+  percentage: number;
+  severity: string;
+  upperLimitPercent: number;
+}
+
+export interface FDC {
+  apiKey: string;
+  dataTypes: string[];
+  endpoint: string;
+}
+
+export interface FattyAcidBalance {
+  epaPlusDhaMg: number;
+  marineOmega3Share: number;
+  omega3Mg: number;
+  omega6Mg: number;
+  omega6To3Ratio: number | null;
+  plantOmega3Share: number;
+  ratioStatus: string;
+}
+
+export interface FetchNutrientDataRequest {
+  currentNutrients: { [key: string]: number };
+  foodDescription: string;
+  profile?: Profile | null;
+}
+
+export interface FetchNutrientDataResponse {
+  changedNutrients: string[];
+  excessNutrients: ExcessNutrient[];
+  nutrientDetails: { [key: string]: NutrientAmount };
+  nutrients: { [key: string]: number };
+  profile: Profile;
+}
+
+export interface FoodSource {
+  confidence?: number;
+  description?: string;
+  id?: string;
+  provider: string;
+}
+
+export interface Gemini {
+  apiKey: string;
+  endpoint: string;
+  model: string;
+}
+
+export interface HealthStatus {
+  checks?: { [key: string]: string };
+  status: string;
+}
+
+export interface IngredientResolved {
+  ingredient: string;
+  macros: { [key: string]: number };
+  nutrientDetails: { [key: string]: NutrientAmount };
+  nutrients: { [key: string]: number };
+  portion: Portion;
+  source?: FoodSource | null;
+}
+
+export interface IngredientsExtracted {
+  ingredients: string[];
+}
+
+export interface JournalEntry {
+  analysis: ProcessFoodResponse;
+  id: string;
+  slot: string;
+  timestamp: string;
+}
+
+export interface JournalEntryRequest {
+  analysis: ProcessFoodResponse;
+  slot: string;
+  timestamp?: string;
+}
+
+export interface Macros {
+  perIngredient: { [key: string]: { [key: string]: number } };
+  targets: { [key: string]: number };
+  total: { [key: string]: number };
+  units: { [key: string]: string };
+}
+
+export interface Metrics {
+  fattyAcids: FattyAcidBalance;
+  protein: ProteinQuality;
+}
+
+export interface NutrientAmount {
+  amount: number;
+  percentage: number;
+  target: number;
+  unit: string;
+}
+
+export interface NutrientGap {
+  expectedPercent: number;
+  gapPercent: number;
+  nutrient: string;
+  percentage: number;
+  status: string;
+  thresholdPercent: number;
+}
+
+export interface NutrientTrend {
+  averagePercent: number;
+  daysBelowTarget: number;
+  daysLogged: number;
+}
+
+export interface NutrientTrends {
+  end: string;
+  windows: TrendWindow[];
+}
+
+export interface Nutritionix {
+  appId: string;
+  appKey: string;
+  endpoint: string;
+}
+
+export interface Portion {
+  assumed: boolean;
+  grams?: number;
+  quantity: number;
+  unit: string;
+}
+
+export interface ProcessFoodRequest {
+  context?: string;
+  foodDescription: string;
+  profile?: Profile | null;
+}
+
+export interface ProcessFoodResponse {
+  complementaryProteins: string[];
+  context: string;
+  derived: Metrics;
+  excessNutrients: ExcessNutrient[];
+  gaps: NutrientGap[];
+  ingredients: string[];
+  macros: Macros;
+  missingNutrients: string[];
+  nutrientDetails: { [key: string]: { [key: string]: NutrientAmount } };
+  nutrients: { [key: string]: { [key: string]: number } };
+  portions: { [key: string]: Portion };
+  profile: Profile;
+  schemaVersion: number;
+  sources?: { [key: string]: FoodSource };
+  suggestions: string[];
+  untrackedNutrients: string[];
+}
+
+export interface Profile {
+  age: number;
+  lactating?: boolean;
+  lifeStage?: string;
+  pregnant?: boolean;
+  sex?: string;
+  weightKg?: number;
+}
+
+export interface ProteinQuality {
+  coverage: number;
+  meal: AminoAcidScore;
+  perIngredient: { [key: string]: AminoAcidScore };
+  unscored?: string[];
+}
+
+export interface Provider {
+  concurrency: number;
+  fallback: string;
+  minConfidence: number;
+  name: string;
+}
+
+export interface RecommendationsReady {
+  complementaryProteins: string[];
+  suggestions: string[];
+}
+
+export interface Server {
+  addr: string;
+  allowedOrigins: string[];
+  shutdownTimeout: string;
+}
+
+export interface Session {
+  account: Account;
+  expiresAt: string;
+  token: string;
+}
+
+export interface TotalsComputed {
+  excessNutrients: ExcessNutrient[];
+  gaps: NutrientGap[];
+  macros: Macros;
+  missingNutrients: string[];
+  nutrients: { [key: string]: number };
+  untrackedNutrients: string[];
+}
+
+export interface TrendWindow {
+  chronicDeficiencies: string[];
+  days: number;
+  end: string;
+  loggedDays: string[];
+  nutrients: { [key: string]: NutrientTrend };
+  start: string;
+  suggestions: string[];
+}
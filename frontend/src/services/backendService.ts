@@ -1,34 +1,144 @@
 // The-Nutrimancers-Codex/frontend/src/services/backendService.ts
 
 import axios from 'axios';
+import type {
+  AnalysisFailed,
+  Credentials,
+  FetchNutrientDataRequest,
+  FetchNutrientDataResponse,
+  IngredientResolved,
+  IngredientsExtracted,
+  ProcessFoodRequest,
+  ProcessFoodResponse,
+  RecommendationsReady,
+  Session,
+  TotalsComputed,
+} from './api';
 
-interface ProcessFoodResponse {
-  ingredients: string[];
-  nutrients: { [ingredient: string]: { [nutrient: string]: number } };
-  missingNutrients: string[];
-  suggestions: string[];
+export type { FetchNutrientDataResponse, ProcessFoodResponse };
+
+const API_BASE = 'https://Nutrimancer-env.eba-mhnjc34h.us-east-1.elasticbeanstalk.com/v1';
+
+// Analysis needs an account: the bearer token from logIn is kept across reloads
+const TOKEN_KEY = 'nutrimancerToken';
+
+export const isLoggedIn = (): boolean => localStorage.getItem(TOKEN_KEY) !== null;
+
+const authHeaders = (): { Authorization?: string } => {
+  const token = localStorage.getItem(TOKEN_KEY);
+  return token ? { Authorization: `Bearer ${token}` } : {};
+};
+
+export const register = async (username: string, password: string): Promise<void> => {
+  try {
+    const request: Credentials = { username, password };
+    await axios.post(`${API_BASE}/accounts`, request);
+  } catch (error: unknown) {
+    throw toError(error, 'An error occurred while registering.');
+  }
+};
+
+export const logIn = async (username: string, password: string): Promise<void> => {
+  try {
+    const request: Credentials = { username, password };
+    const response = await axios.post<Session>(`${API_BASE}/sessions`, request);
+    localStorage.setItem(TOKEN_KEY, response.data.token);
+  } catch (error: unknown) {
+    throw toError(error, 'An error occurred while logging in.');
+  }
+};
+
+export interface ProcessFoodStreamHandlers {
+  onIngredients?: (event: IngredientsExtracted) => void;
+  onIngredient?: (event: IngredientResolved) => void;
+  onTotals?: (event: TotalsComputed) => void;
+  onRecommendations?: (event: RecommendationsReady) => void;
 }
 
-export const processFood = async (foodDescription: string): Promise<ProcessFoodResponse> => {
+// Meal analysis with progress: POST can't use EventSource, so the SSE stream is read with fetch
+export const processFoodStream = async (
+  foodDescription: string,
+  handlers: ProcessFoodStreamHandlers
+): Promise<ProcessFoodResponse> => {
+  const request: ProcessFoodRequest = { foodDescription };
+  const response = await fetch(`${API_BASE}/meal-analyses/stream`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json', ...authHeaders() },
+    body: JSON.stringify(request),
+  });
+  if (response.status === 401) {
+    localStorage.removeItem(TOKEN_KEY);
+  }
+  if (!response.ok || !response.body) {
+    const body = await response.json().catch(() => null);
+    throw new Error(body?.error ?? 'An error occurred while processing the food.');
+  }
+
+  const reader = response.body.pipeThrough(new TextDecoderStream()).getReader();
+  let buffer = '';
+  for (;;) {
+    const { value, done } = await reader.read();
+    if (done) break;
+    buffer += value;
+    let boundary;
+    while ((boundary = buffer.indexOf('\n\n')) >= 0) {
+      const message = buffer.slice(0, boundary);
+      buffer = buffer.slice(boundary + 2);
+      const event = message.match(/^event: (.*)$/m)?.[1];
+      const data = JSON.parse(message.match(/^data: (.*)$/m)?.[1] ?? 'null');
+      switch (event) {
+        case 'ingredients':
+          handlers.onIngredients?.(data as IngredientsExtracted);
+          break;
+        case 'ingredient':
+          handlers.onIngredient?.(data as IngredientResolved);
+          break;
+        case 'totals':
+          handlers.onTotals?.(data as TotalsComputed);
+          break;
+        case 'recommendations':
+          handlers.onRecommendations?.(data as RecommendationsReady);
+          break;
+        case 'result':
+          return data as ProcessFoodResponse;
+        case 'error':
+          throw new Error((data as AnalysisFailed).error);
+      }
+    }
+  }
+  throw new Error('The analysis stream ended without a result.');
+};
+
+export const lookupFood = async (
+  foodDescription: string,
+  currentNutrients: { [nutrient: string]: number }
+): Promise<FetchNutrientDataResponse> => {
   try {
-    const response = await axios.post<ProcessFoodResponse>(`https://Nutrimancer-env.eba-mhnjc34h.us-east-1.elasticbeanstalk.com/process-food`, {
-      foodDescription,
+    const request: FetchNutrientDataRequest = { foodDescription, currentNutrients };
+    const response = await axios.post<FetchNutrientDataResponse>(`${API_BASE}/food-lookups`, request, {
+      headers: authHeaders(),
     });
     return response.data;
   } catch (error: unknown) {
-    if (axios.isAxiosError(error)) {
-      console.error('Full error response:', error.response);
-      let detailedError = 'An error occurred while processing the food.';
-      if (error.response?.data?.error) {
-        detailedError = error.response.data.error;
-      } else if (typeof error.response?.data === 'string') {
-        detailedError = error.response.data;
-      }
-      throw new Error(detailedError);
-    } else {
-      throw new Error('An unexpected error occurred.');
+    throw toError(error, 'An error occurred while fetching nutrient data.');
+  }
+};
+
+const toError = (error: unknown, fallback: string): Error => {
+  if (axios.isAxiosError(error)) {
+    if (error.response?.status === 401) {
+      localStorage.removeItem(TOKEN_KEY); // expired or revoked - log in again
+    }
+    console.error('Full error response:', error.response);
+    let detailedError = fallback;
+    if (error.response?.data?.error) {
+      detailedError = error.response.data.error;
+    } else if (typeof error.response?.data === 'string') {
+      detailedError = error.response.data;
     }
+    return new Error(detailedError);
   }
+  return new Error('An unexpected error occurred.');
 };
 
 